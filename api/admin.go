@@ -0,0 +1,82 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resetHandler handles the POST /admin/reset endpoint
+func (a *API) resetHandler(c *gin.Context) {
+	if err := a.indexer.Reset(); err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	log.Printf("Blocks table reset by admin request from %s", c.ClientIP())
+	c.Status(http.StatusNoContent)
+}
+
+// compactHandler handles the POST /admin/compact endpoint
+func (a *API) compactHandler(c *gin.Context) {
+	before, err := strconv.ParseInt(c.Query("before"), 10, 64)
+	if err != nil || before <= 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'before' height")
+		return
+	}
+
+	compacted, err := a.indexer.CompactDetails(c.Request.Context(), before)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{"before": before, "compacted": compacted})
+}
+
+// Limit bounds for /admin/backfill-details
+const (
+	defaultBackfillDetailsLimit = 1000
+	maxBackfillDetailsLimit     = 100000
+)
+
+// backfillDetailsHandler handles the POST /admin/backfill-details endpoint
+func (a *API) backfillDetailsHandler(c *gin.Context) {
+	from, err := strconv.ParseInt(c.DefaultQuery("from", "0"), 10, 64)
+	if err != nil || from < 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'from' height")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultBackfillDetailsLimit)))
+	if err != nil || limit <= 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'limit'")
+		return
+	}
+	if limit > maxBackfillDetailsLimit {
+		limit = maxBackfillDetailsLimit
+	}
+
+	result, err := a.indexer.BackfillDetails(c.Request.Context(), from, limit)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	log.Printf("Backfilled details of %d blocks from height %d by admin request from %s (%d failed)", result.Healed, from, c.ClientIP(), result.Failed)
+	respondJSON(c, http.StatusOK, result)
+}
+
+// maintenanceHandler handles the POST /admin/maintenance endpoint
+func (a *API) maintenanceHandler(c *gin.Context) {
+	vacuum := c.Query("vacuum") == "true"
+	maintenance, err := a.indexer.MaintainBlocks(c.Request.Context(), vacuum)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	log.Printf("Blocks table maintenance (vacuum: %t) by admin request from %s took %dms", vacuum, c.ClientIP(), maintenance.DurationMS)
+	respondJSON(c, http.StatusOK, maintenance)
+}
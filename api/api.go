@@ -1,50 +1,640 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/muhammadfarhankt/omniFlix/config"
 	"github.com/muhammadfarhankt/omniFlix/indexer"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // API struct to hold dependencies
 type API struct {
-	indexer *indexer.Indexer
+	indexer    BlockStore
+	features   features
+	authConfig authConfig
+	version    string
+	// stallWindow is how long /ready tolerates no stored block while the chain advances
+	stallWindow time.Duration
+	// maxRangeFetches caps the heights /blocks/range fetches on demand per request
+	maxRangeFetches int
+	// verboseErrors returns unexpected errors verbatim instead of a generic message (APP_ENV=development)
+	verboseErrors bool
+	graphQLSchema graphql.Schema
 }
 
-// NewAPI creates a new API instance
-func NewAPI(indexer *indexer.Indexer) *API {
-	return &API{indexer: indexer}
+// NewAPI creates a new API instance serving the blocks of indexer for the given build version
+func NewAPI(indexer BlockStore, version string) *API {
+	return &API{
+		indexer:    indexer,
+		features:   loadFeatures(),
+		authConfig: loadAuthConfig(),
+		version:    version,
+
+		stallWindow:     config.Duration("READY_STALL_WINDOW", 5*time.Minute),
+		maxRangeFetches: config.Int("RANGE_MAX_FETCHES", 50),
+		verboseErrors:   config.String("APP_ENV", "production") == "development",
+	}
 }
 
-// Start starts the API server
-func (a *API) Start(addr string) {
+// Start starts the API server and blocks until ctx is cancelled, then shuts it down gracefully
+func (a *API) Start(ctx context.Context, addr string) error {
+	router, err := a.newRouter()
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           router,
+		ReadHeaderTimeout: config.Duration("SERVER_READ_HEADER_TIMEOUT", 10*time.Second),
+		ReadTimeout:       config.Duration("SERVER_READ_TIMEOUT", 30*time.Second),
+		// Streaming exports need a generous but bounded write timeout
+		WriteTimeout: config.Duration("SERVER_WRITE_TIMEOUT", 5*time.Minute),
+		IdleTimeout:  config.Duration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Starting API server on %s", addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("error running API server: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down API server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Duration("SERVER_SHUTDOWN_TIMEOUT", 10*time.Second))
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("error shutting down API server: %w", err)
+	}
+	return nil
+}
+
+// newRouter builds the GraphQL schema and the router serving every endpoint
+func (a *API) newRouter() (*gin.Engine, error) {
+	schema, err := a.newGraphQLSchema()
+	if err != nil {
+		return nil, fmt.Errorf("error building GraphQL schema: %w", err)
+	}
+	a.graphQLSchema = schema
+
 	router := gin.Default()
+	router.Use(requestID())
+	router.Use(errorVerbosity(a.verboseErrors))
+	router.Use(requestMetrics())
+	router.Use(cors(loadCORSConfig()))
+	router.Use(maxInFlight(config.Int("API_MAX_IN_FLIGHT", 200)))
+	router.Use(maxBodySize(config.Int64("MAX_BODY_BYTES", defaultMaxBodyBytes)))
+
+	router.NoRoute(func(c *gin.Context) {
+		respondError(c, http.StatusNotFound, codeNotFound, "route not found")
+	})
+
+	// Liveness and readiness probes
+	router.GET("/health", a.healthHandler)
+	router.GET("/ready", a.readyHandler)
+
+	// Deployment information
+	router.GET("/info", a.getInfoHandler)
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// API endpoint to fetch, compare, store, and show block details
+	router.GET("/block/earliest", a.getEarliestBlockHandler)
+	router.GET("/block/latest", a.getLatestBlockHandler)
 	router.GET("/block/:height", a.getBlockDetailsHandler)
+	router.GET("/block/:height/size", a.getBlockSizeHandler)
+	router.GET("/block/:height/app-hash", a.getBlockAppHashHandler)
+	router.GET("/block/:height/neighbors-same-proposer", a.getBlockProposerRunHandler)
+	router.GET("/block/:height/extract", a.getBlockExtractHandler)
+	router.GET("/block/:height/verify", a.getBlockVerifyHandler)
+	router.PUT("/block/:height", a.auth(authGroupWrite), a.putBlockHandler)
+	router.POST("/block/:height/tags", a.auth(authGroupWrite), a.postBlockTagsHandler)
+
+	// Proposer activity
+	router.GET("/proposer/:address/timeline", a.getProposerTimelineHandler)
+	router.GET("/proposer/:address/recent", a.getProposerRecentHandler)
+	router.GET("/proposers", a.getProposersHandler)
+	router.GET("/proposers/blocks", a.getProposersBlocksHandler)
+	router.GET("/proposers/sequence", a.getProposerSequenceHandler)
+	router.GET("/validators/:valoper/blocks", a.getValidatorBlocksHandler)
+
+	// Block listings
+	router.GET("/blocks", a.getBlocksHandler)
+	router.GET("/blocks/count", a.getBlocksCountHandler)
+	router.GET("/blocks/busiest", a.getBusiestBlocksHandler)
+	router.GET("/blocks/latest", a.getLatestBlocksHandler)
+	router.GET("/blocks/range", a.getBlocksRangeHandler)
+	router.GET("/blocks/checksum", a.getBlocksChecksumHandler)
+	router.GET("/blocks/search-details", a.getSearchDetailsHandler)
+
+	// GraphQL queries over blocks and their transactions and events
+	router.GET("/graphql", a.graphQLHandler)
+	router.POST("/graphql", a.graphQLHandler)
+
+	// Indexing coverage
+	router.GET("/gaps/ranges", a.getGapRangesHandler)
+	router.GET("/progress", a.getProgressHandler)
+	router.GET("/status", a.getStatusHandler)
+
+	// Bulk export of a height range
+	if a.features.enabled(featureExport) {
+		router.GET("/blocks/range.ndjson", a.auth(featureExport), a.getBlocksRangeNDJSONHandler)
+	}
+
+	// Aggregate statistics over a height range
+	if a.features.enabled(featureStats) {
+		stats := router.Group("/stats", a.auth(featureStats))
+		stats.GET("/size", a.getSizeStatsHandler)
+		stats.GET("/gas", a.getGasStatsHandler)
+		stats.GET("/hourly", a.getHourlyStatsHandler)
+		stats.GET("/proposers", a.getProposerStatsHandler)
+		stats.GET("/proposers/last-seen", a.getProposersLastSeenHandler)
+		stats.GET("/proposer-streaks", a.getProposerStreaksHandler)
+		stats.GET("/decentralization", a.getDecentralizationHandler)
+		stats.GET("/tx-types", a.getTxTypeStatsHandler)
+		stats.GET("/participation", a.getParticipationHandler)
+		stats.GET("/tx-histogram", a.getTxHistogramHandler)
+		stats.GET("/slow-blocks", a.getSlowBlocksHandler)
+	}
+
+	// Admin endpoints, gated behind a bearer token
+	admin := router.Group("/admin", a.auth(authGroupAdmin))
+	admin.POST("/reset", a.resetHandler)
+	admin.POST("/compact", a.compactHandler)
+	admin.POST("/backfill-details", a.backfillDetailsHandler)
+	admin.POST("/maintenance", a.maintenanceHandler)
 
-	log.Printf("Starting API server on %s", addr)
-	router.Run(addr)
+	return router, nil
 }
 
-// getBlockDetailsHandler handles the /block/:height endpoint
+// parseHeight reads the :height path parameter, rejecting non-positive heights and, with an error
+// wrapping indexer.ErrBlockNotFound, heights above the chain height once it is known
+func (a *API) parseHeight(c *gin.Context) (int64, error) {
+	height, err := strconv.ParseInt(c.Param("height"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block height")
+	}
+	if height <= 0 {
+		return 0, fmt.Errorf("block height must be positive")
+	}
+	if chainHeight := a.indexer.ChainHeight(); chainHeight > 0 && height > chainHeight {
+		return 0, fmt.Errorf("block height %d is above the chain height %d: %w", height, chainHeight, indexer.ErrBlockNotFound)
+	}
+
+	return height, nil
+}
+
+// respondHeightError writes the error of parseHeight: 404 for heights above the chain tip, which are
+// answered without any database or RPC work, and 400 for invalid heights
+func respondHeightError(c *gin.Context, err error) {
+	if errors.Is(err, indexer.ErrBlockNotFound) {
+		respondError(c, http.StatusNotFound, codeBlockNotFound, err.Error())
+		return
+	}
+	respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+}
+
+// getBlockDetailsHandler handles the /block/:height endpoint. A negative height, e.g. /block/-5, is
+// an offset below the highest indexed block.
 func (a *API) getBlockDetailsHandler(c *gin.Context) {
-	heightStr := c.Param("height")
-	height, err := strconv.ParseInt(heightStr, 10, 64)
+	var (
+		height int64
+		err    error
+	)
+	if offset, ok := strings.CutPrefix(c.Param("height"), "-"); ok {
+		height, err = a.resolveTipOffset(c, offset)
+		if err != nil {
+			return
+		}
+	} else {
+		height, err = a.parseHeight(c)
+		if err != nil {
+			respondHeightError(c, err)
+			return
+		}
+	}
+
+	refresh, err := strconv.ParseBool(c.DefaultQuery("refresh", "false"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid block height"})
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'refresh'")
+		return
+	}
+	fields, err := parseFields(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	// ?refresh=true bypasses the stored row and re-fetches the block from the blockchain
+	if refresh {
+		blockDetails, err := a.indexer.RefreshBlockDetails(height)
+		if err != nil {
+			respondInternalError(c, err)
+			return
+		}
+		respondBlockFrom(c, blockDetails, fields, indexer.SourceRPC)
 		return
 	}
 
 	// Fetch block details (from DB or blockchain)
-	blockDetails, err := a.indexer.GetBlockDetails(height)
+	blockDetails, source, err := a.indexer.GetBlockDetails(height)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondBlockFrom(c, *blockDetails, fields, source)
+}
+
+// getBlockSizeHandler handles the /block/:height/size endpoint
+func (a *API) getBlockSizeHandler(c *gin.Context) {
+	height, err := a.parseHeight(c)
+	if err != nil {
+		respondHeightError(c, err)
+		return
+	}
+
+	blockDetails, _, err := a.indexer.GetBlockDetails(height)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"height":           blockDetails.Height,
+		"block_size_bytes": blockDetails.BlockSizeBytes,
+	})
+}
+
+// getBlockAppHashHandler handles the /block/:height/app-hash endpoint
+func (a *API) getBlockAppHashHandler(c *gin.Context) {
+	height, err := a.parseHeight(c)
+	if err != nil {
+		respondHeightError(c, err)
+		return
+	}
+
+	blockDetails, _, err := a.indexer.GetBlockDetails(height)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"height":   blockDetails.Height,
+		"app_hash": blockDetails.AppHash,
+	})
+}
+
+// Bounds of the limit parameter of /block/:height/neighbors-same-proposer, per direction
+const (
+	defaultProposerRunLimit = 100
+	maxProposerRunLimit     = 1000
+)
+
+// getBlockProposerRunHandler handles the /block/:height/neighbors-same-proposer endpoint
+func (a *API) getBlockProposerRunHandler(c *gin.Context) {
+	height, err := a.parseHeight(c)
+	if err != nil {
+		respondHeightError(c, err)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultProposerRunLimit)))
+	if err != nil || limit <= 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'limit'")
+		return
+	}
+	if limit > maxProposerRunLimit {
+		limit = maxProposerRunLimit
+	}
+
+	run, err := a.indexer.GetProposerRun(height, limit)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, run)
+}
+
+// getBlockVerifyHandler handles the /block/:height/verify endpoint
+func (a *API) getBlockVerifyHandler(c *gin.Context) {
+	height, err := a.parseHeight(c)
+	if err != nil {
+		respondHeightError(c, err)
+		return
+	}
+
+	verification, err := a.indexer.VerifyBlock(height)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, verification)
+}
+
+// getGapRangesHandler handles the /gaps/ranges endpoint
+func (a *API) getGapRangesHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	ranges, err := a.indexer.GetMissingRanges(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	var missing int64
+	for _, r := range ranges {
+		missing += r.To - r.From + 1
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"from":           from,
+		"to":             to,
+		"missing_blocks": missing,
+		"ranges":         ranges,
+	})
+}
+
+// getBlocksChecksumHandler handles the /blocks/checksum endpoint
+func (a *API) getBlocksChecksumHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	checksum, err := a.indexer.GetRangeChecksum(c.Request.Context(), from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, checksum)
+}
+
+// getEarliestBlockHandler handles the /block/earliest endpoint
+func (a *API) getEarliestBlockHandler(c *gin.Context) {
+	a.respondEdgeBlock(c, a.indexer.GetEarliestIndexedBlock)
+}
+
+// getLatestBlockHandler handles the /block/latest endpoint; ?offset=N returns the block N below it
+func (a *API) getLatestBlockHandler(c *gin.Context) {
+	offset := c.Query("offset")
+	if offset == "" {
+		a.respondEdgeBlock(c, a.indexer.GetLatestIndexedBlock)
+		return
+	}
+
+	fields, err := parseFields(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	height, err := a.resolveTipOffset(c, offset)
+	if err != nil {
+		return
+	}
+	blockDetails, _, err := a.indexer.GetBlockDetails(height)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondBlock(c, *blockDetails, fields)
+}
+
+// resolveTipOffset returns the height offset blocks below the highest indexed block,
+// responding with an error itself when the offset is invalid or out of the indexed range
+func (a *API) resolveTipOffset(c *gin.Context, offset string) (int64, error) {
+	n, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil || n < 0 {
+		err = fmt.Errorf("invalid offset from the indexed tip")
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return 0, err
+	}
+
+	height, err := a.indexer.ResolveTipOffset(n)
+	if err != nil {
+		respondInternalError(c, err)
+		return 0, err
+	}
+	return height, nil
+}
+
+// respondEdgeBlock writes the block returned by fetch, or 404 when nothing is indexed
+func (a *API) respondEdgeBlock(c *gin.Context, fetch func() (*indexer.BlockDetails, error)) {
+	fields, err := parseFields(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	blockDetails, err := fetch()
+	if err != nil {
+		if errors.Is(err, indexer.ErrBlockNotFound) {
+			respondError(c, http.StatusNotFound, codeNotFound, "no blocks indexed")
+			return
+		}
+		respondInternalError(c, err)
+		return
+	}
+
+	respondBlock(c, *blockDetails, fields)
+}
+
+// getInfoHandler handles the /info endpoint
+func (a *API) getInfoHandler(c *gin.Context) {
+	cfg := a.indexer.Config()
+
+	// The chain id is informational, so an unreachable node must not fail the endpoint
+	chainID, err := a.indexer.ChainID()
+	if err != nil {
+		log.Printf("Error fetching chain id: %v", err)
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"version":           a.version,
+		"chain_id":          chainID,
+		"rpc_urls":          cfg.RPCURLs,
+		"rest_url":          cfg.RESTURL,
+		"concurrency":       cfg.Concurrency,
+		"fetch_mode":        cfg.FetchMode,
+		"grpc_addr":         cfg.GRPCAddr,
+		"indexing_interval": cfg.Interval.String(),
+	})
+}
+
+// getBlocksHandler handles the /blocks endpoint
+func (a *API) getBlocksHandler(c *gin.Context) {
+	filter, err := parseBlockFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	a.respondBlockList(c, filter)
+}
+
+// respondBlockList writes the page of blocks matching filter selected by the limit, offset, sort and fields
+// query parameters, along with the pagination headers
+func (a *API) respondBlockList(c *gin.Context, filter indexer.BlockFilter) {
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	sort := c.DefaultQuery("sort", indexer.SortHeightDesc)
+	if !indexer.ValidBlockSort(sort) {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'sort', expected one of height_asc, height_desc, txs_desc, time_desc")
+		return
+	}
+	fields, err := parseFields(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	blocks, err := a.indexer.ListBlocks(filter, sort, limit, offset, fields)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	total, exact, err := a.countBlocks(c, filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	if !exact {
+		c.Header("X-Total-Count-Estimated", "true")
+	}
+	setPaginationHeaders(c, limit, offset, total)
+
+	respondBlocks(c, blocks, fields)
+}
+
+// countBlocks counts the blocks matching filter. Unfiltered counts are estimated unless the request
+// sets exact=true, since an exact count scans the whole table. It reports whether the count is exact.
+func (a *API) countBlocks(c *gin.Context, filter indexer.BlockFilter) (int64, bool, error) {
+	if filter == (indexer.BlockFilter{}) {
+		return a.indexer.TotalBlocks(c.Query("exact") == "true")
+	}
+	count, err := a.indexer.CountBlocks(filter)
+	return count, true, err
+}
+
+// getBlocksCountHandler handles the /blocks/count endpoint
+func (a *API) getBlocksCountHandler(c *gin.Context) {
+	filter, err := parseBlockFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	count, exact, err := a.countBlocks(c, filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{"count": count, "exact": exact})
+}
+
+// Limit bounds for /blocks/busiest
+const (
+	defaultBusiestLimit = 10
+	maxBusiestLimit     = 100
+)
+
+// getBusiestBlocksHandler handles the /blocks/busiest endpoint
+func (a *API) getBusiestBlocksHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultBusiestLimit)))
+	if err != nil || limit <= 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'limit'")
+		return
+	}
+	if limit > maxBusiestLimit {
+		limit = maxBusiestLimit
+	}
+	fields, err := parseFields(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	blocks, err := a.indexer.ListBlocks(indexer.BlockFilter{From: from, To: to}, indexer.SortTxsDesc, limit, 0, fields)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondBlocks(c, blocks, fields)
+}
+
+// Bounds of the n parameter of /blocks/latest
+const (
+	defaultLatestBlocks = 10
+	maxLatestBlocks     = 100
+)
+
+// getLatestBlocksHandler handles the /blocks/latest endpoint
+func (a *API) getLatestBlocksHandler(c *gin.Context) {
+	n, err := strconv.Atoi(c.DefaultQuery("n", strconv.Itoa(defaultLatestBlocks)))
+	if err != nil || n <= 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'n'")
+		return
+	}
+	if n > maxLatestBlocks {
+		n = maxLatestBlocks
+	}
+	fields, err := parseFields(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	blocks, err := a.indexer.GetLatestBlocks(n)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondBlocks(c, blocks, fields)
+}
+
+// getProgressHandler handles the /progress endpoint
+func (a *API) getProgressHandler(c *gin.Context) {
+	progress, err := a.indexer.GetProgress()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondUnexpectedError(c, http.StatusServiceUnavailable, codeUnavailable, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, blockDetails)
+	respondJSON(c, http.StatusOK, progress)
 }
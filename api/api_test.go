@@ -0,0 +1,323 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testBlock returns an indexed block at height
+func testBlock(height int64) indexer.BlockDetails {
+	return indexer.BlockDetails{
+		Height:          height,
+		BlockID:         fmt.Sprintf("%064X", height),
+		NumTransactions: int(height % 3),
+		NumEvents:       int(height % 5),
+		Proposer:        "6B2FB9E8D4A9F1C2A5C7E3D1B0F4A6C8E2D9B7A1",
+		BlockTime:       time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(height) * 6 * time.Second),
+		CreatedAt:       time.Date(2024, 3, 1, 12, 0, 1, 0, time.UTC),
+		UpdatedAt:       time.Date(2024, 3, 1, 12, 0, 1, 0, time.UTC),
+	}
+}
+
+// serve sends a GET request for path to the API router of store
+func serve(t *testing.T, store BlockStore, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	router, err := NewAPI(store, "test").newRouter()
+	if err != nil {
+		t.Fatalf("newRouter: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+// decode decodes the JSON body of recorder into v
+func decode(t *testing.T, recorder *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(recorder.Body.Bytes(), v); err != nil {
+		t.Fatalf("error decoding %q: %v", recorder.Body.String(), err)
+	}
+}
+
+// assertError checks that recorder holds an APIError with status and code
+func assertError(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) APIError {
+	t.Helper()
+	if recorder.Code != status {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, status, recorder.Body.String())
+	}
+	var body APIErrorResponse
+	decode(t, recorder, &body)
+	if body.Error.Code != code {
+		t.Errorf("error code = %q, want %q", body.Error.Code, code)
+	}
+	if body.Error.RequestID == "" {
+		t.Errorf("error has no request id")
+	}
+	return body.Error
+}
+
+func TestGetBlock(t *testing.T) {
+	store := newFakeStore(testBlock(10), testBlock(11), testBlock(12))
+	store.chain[20] = testBlock(20)
+
+	for _, tc := range []struct {
+		path       string
+		wantHeight int64
+		wantSource indexer.BlockSource
+	}{
+		{"/block/-2", 10, indexer.SourceDB},
+		{"/block/11", 11, indexer.SourceDB},
+		{"/block/20", 20, indexer.SourceRPC},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			recorder := serve(t, store, tc.path)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+			}
+			var block struct {
+				Height  int64               `json:"height"`
+				BlockID string              `json:"block_id"`
+				Source  indexer.BlockSource `json:"source"`
+			}
+			decode(t, recorder, &block)
+			if block.Height != tc.wantHeight || block.BlockID != testBlock(tc.wantHeight).BlockID || block.Source != tc.wantSource {
+				t.Errorf("block = %+v, want height %d from %s", block, tc.wantHeight, tc.wantSource)
+			}
+		})
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		recorder := serve(t, store, "/block/12?fields=height,num_events")
+		var block map[string]json.RawMessage
+		decode(t, recorder, &block)
+		if len(block) != 3 || string(block["height"]) != "12" || string(block["num_events"]) != "2" || string(block["source"]) != `"db"` {
+			t.Errorf("projected block = %s", recorder.Body.String())
+		}
+	})
+}
+
+func TestGetBlockErrors(t *testing.T) {
+	store := newFakeStore(testBlock(10))
+	store.errs[30] = fmt.Errorf("error fetching block: %w: height 30 is not available, lowest height is 40", indexer.ErrBlockPruned)
+	store.errs[31] = fmt.Errorf("error fetching block results: %w", indexer.ErrRateLimited)
+	store.errs[32] = fmt.Errorf("error fetching block details from database: %w", &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"})
+	store.errs[33] = errors.New("error fetching block details from database: dial tcp 10.0.0.5:5432: connection refused")
+
+	for _, tc := range []struct {
+		path        string
+		status      int
+		code        string
+		wantMessage string
+	}{
+		{"/block/abc", http.StatusBadRequest, codeInvalidRequest, "invalid block height"},
+		{"/block/25", http.StatusNotFound, codeBlockNotFound, indexer.ErrBlockNotFound.Error()},
+		{"/block/-5", http.StatusNotFound, codeBlockNotFound, indexer.ErrBlockNotFound.Error()},
+		{"/block/30", http.StatusGone, codeBlockPruned, indexer.ErrBlockPruned.Error()},
+		{"/block/31", http.StatusTooManyRequests, codeRateLimited, indexer.ErrRateLimited.Error()},
+		{"/block/32", http.StatusGatewayTimeout, codeTimeout, ""},
+		{"/block/33", http.StatusInternalServerError, codeInternal, ""},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			recorder := serve(t, store, tc.path)
+			apiErr := assertError(t, recorder, tc.status, tc.code)
+			if tc.wantMessage != "" && apiErr.Message != tc.wantMessage {
+				t.Errorf("message = %q, want %q", apiErr.Message, tc.wantMessage)
+			}
+			// Database and RPC details never reach clients outside development
+			if strings.Contains(apiErr.Message, "10.0.0.5") || strings.Contains(apiErr.Message, "lowest height") {
+				t.Errorf("message leaks error details: %q", apiErr.Message)
+			}
+		})
+	}
+
+	if recorder := serve(t, store, "/block/31"); recorder.Header().Get("Retry-After") == "" {
+		t.Errorf("429 without Retry-After")
+	}
+}
+
+func TestGetBlocks(t *testing.T) {
+	store := newFakeStore()
+	for height := int64(1); height <= 25; height++ {
+		store.blocks[height] = testBlock(height)
+	}
+	other := testBlock(26)
+	other.Proposer = "A1B7D9E2C8A6F4B0D1E3C7A5C2F1A9D4E8B9FB26"
+	store.blocks[26] = other
+
+	heights := func(t *testing.T, recorder *httptest.ResponseRecorder) []int64 {
+		t.Helper()
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+		}
+		var blocks []indexer.BlockDetails
+		decode(t, recorder, &blocks)
+		heights := make([]int64, 0, len(blocks))
+		for _, block := range blocks {
+			heights = append(heights, block.Height)
+		}
+		return heights
+	}
+
+	t.Run("page", func(t *testing.T) {
+		recorder := serve(t, store, "/blocks?limit=3&offset=2")
+		if got := fmt.Sprint(heights(t, recorder)); got != "[24 23 22]" {
+			t.Errorf("heights = %s, want [24 23 22]", got)
+		}
+		if total := recorder.Header().Get("X-Total-Count"); total != "26" {
+			t.Errorf("X-Total-Count = %q, want 26", total)
+		}
+		if link := recorder.Header().Get("Link"); !strings.Contains(link, `offset=5>; rel="next"`) || !strings.Contains(link, `offset=0>; rel="prev"`) {
+			t.Errorf("Link = %q", link)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		recorder := serve(t, store, "/blocks?sort=height_asc&proposer="+other.Proposer)
+		if got := fmt.Sprint(heights(t, recorder)); got != "[26]" {
+			t.Errorf("heights = %s, want [26]", got)
+		}
+		if total := recorder.Header().Get("X-Total-Count"); total != "1" {
+			t.Errorf("X-Total-Count = %q, want 1", total)
+		}
+	})
+
+	for _, path := range []string{"/blocks?sort=random", "/blocks?limit=0", "/blocks?from=10&to=5", "/blocks?proposer=nope"} {
+		t.Run(path, func(t *testing.T) {
+			assertError(t, serve(t, store, path), http.StatusBadRequest, codeInvalidRequest)
+		})
+	}
+
+	t.Run("store error", func(t *testing.T) {
+		failing := newFakeStore()
+		failing.err = errors.New("pq: relation \"blocks\" does not exist")
+		apiErr := assertError(t, serve(t, failing, "/blocks"), http.StatusInternalServerError, codeInternal)
+		if strings.Contains(apiErr.Message, "relation") {
+			t.Errorf("message leaks the database error: %q", apiErr.Message)
+		}
+	})
+}
+
+func TestGetProposerStats(t *testing.T) {
+	store := newFakeStore()
+	for height := int64(1); height <= 10; height++ {
+		block := testBlock(height)
+		switch {
+		case height <= 5:
+			block.Proposer = strings.Repeat("A", 40)
+		case height <= 8:
+			block.Proposer = strings.Repeat("B", 40)
+		default:
+			block.Proposer = strings.Repeat("C", 40)
+		}
+		store.blocks[height] = block
+	}
+
+	recorder := serve(t, store, "/stats/proposers?from=1&to=10&limit=1&offset=1")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var stats []indexer.ProposerStats
+	decode(t, recorder, &stats)
+	if want := []indexer.ProposerStats{{Proposer: strings.Repeat("B", 40), Blocks: 3}}; len(stats) != 1 || stats[0] != want[0] {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+	// The headers count proposers, not blocks
+	if total := recorder.Header().Get("X-Total-Count"); total != "3" {
+		t.Errorf("X-Total-Count = %q, want 3", total)
+	}
+	if link := recorder.Header().Get("Link"); !strings.Contains(link, `offset=2&to=10>; rel="next"`) || !strings.Contains(link, `from=1&limit=1&offset=0&to=10>; rel="prev"`) {
+		t.Errorf("Link = %q", link)
+	}
+}
+
+func TestParseHeight(t *testing.T) {
+	for _, tc := range []struct {
+		path        string
+		chainHeight int64
+		status      int
+		// fetches is the number of GetBlockDetails calls the request makes
+		fetches int
+	}{
+		{"/block/0", 1000, http.StatusBadRequest, 0},
+		{"/block/0/size", 1000, http.StatusBadRequest, 0},
+		// A negative height is an offset below the indexed tip on /block/:height only
+		{"/block/-1", 1000, http.StatusOK, 1},
+		{"/block/-1/size", 1000, http.StatusBadRequest, 0},
+		// Heights above the known chain height are rejected without any database or RPC work
+		{"/block/99999999999999", 1000, http.StatusNotFound, 0},
+		{"/block/99999999999999/size", 1000, http.StatusNotFound, 0},
+		// Until the chain height is known, the store decides
+		{"/block/99999999999999", 0, http.StatusNotFound, 1},
+		{"/block/99999999999999999999", 1000, http.StatusBadRequest, 0},
+	} {
+		t.Run(fmt.Sprintf("%s at chain height %d", tc.path, tc.chainHeight), func(t *testing.T) {
+			store := newFakeStore(testBlock(10), testBlock(11))
+			store.chainHeight = tc.chainHeight
+
+			recorder := serve(t, store, tc.path)
+			if recorder.Code != tc.status {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tc.status, recorder.Body.String())
+			}
+			if fetches := store.calls["GetBlockDetails"]; fetches != tc.fetches {
+				t.Errorf("GetBlockDetails called %d times, want %d", fetches, tc.fetches)
+			}
+			if tc.status == http.StatusOK {
+				var block indexer.BlockDetails
+				decode(t, recorder, &block)
+				if block.Height != 10 {
+					t.Errorf("height = %d, want 10, one below the indexed tip", block.Height)
+				}
+			}
+		})
+	}
+}
+
+func TestGetBlockProposerRun(t *testing.T) {
+	store := newFakeStore()
+	for height := int64(1); height <= 10; height++ {
+		block := testBlock(height)
+		if height > 6 {
+			block.Proposer = "A1B7D9E2C8A6F4B0D1E3C7A5C2F1A9D4E8B9FB26"
+		}
+		store.blocks[height] = block
+	}
+
+	recorder := serve(t, store, "/block/4/neighbors-same-proposer?limit=2")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var run indexer.ProposerRun
+	decode(t, recorder, &run)
+	want := indexer.ProposerRun{Height: 4, Proposer: testBlock(4).Proposer, From: 2, To: 6, Length: 5, Truncated: true}
+	if run != want {
+		t.Errorf("run = %+v, want %+v", run, want)
+	}
+
+	if serve(t, store, "/block/4/neighbors-same-proposer"); store.runLimit != defaultProposerRunLimit {
+		t.Errorf("default limit = %d, want %d", store.runLimit, defaultProposerRunLimit)
+	}
+	if serve(t, store, "/block/4/neighbors-same-proposer?limit=100000"); store.runLimit != maxProposerRunLimit {
+		t.Errorf("capped limit = %d, want %d", store.runLimit, maxProposerRunLimit)
+	}
+	assertError(t, serve(t, store, "/block/4/neighbors-same-proposer?limit=0"), http.StatusBadRequest, codeInvalidRequest)
+
+	// Heights that are not indexed are a 404, not a 500
+	apiErr := assertError(t, serve(t, store, "/block/11/neighbors-same-proposer"), http.StatusNotFound, codeBlockNotFound)
+	if apiErr.Message != indexer.ErrBlockNotFound.Error() {
+		t.Errorf("message = %q, want %q", apiErr.Message, indexer.ErrBlockNotFound.Error())
+	}
+}
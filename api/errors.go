@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// Machine-readable error codes of APIError
+const (
+	codeInvalidRequest = "invalid_request"
+	codeUnauthorized   = "unauthorized"
+	codeNotFound       = "not_found"
+	codeBlockNotFound  = "block_not_found"
+	codeBlockPruned    = "block_pruned"
+	codeRateLimited    = "rate_limited"
+	codeUnavailable    = "unavailable"
+	codeTimeout        = "timeout"
+	codeInternal       = "internal_error"
+)
+
+// APIError is the body of every error response
+type APIError struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// APIErrorResponse wraps APIError so clients read the error from a single "error" key
+type APIErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// respondError aborts the request with an APIError
+func respondError(c *gin.Context, status int, code, msg string) {
+	respondErrorDetails(c, status, code, msg, nil)
+}
+
+// respondErrorDetails aborts the request with an APIError carrying details
+func respondErrorDetails(c *gin.Context, status int, code, msg string, details interface{}) {
+	c.Abort()
+	respondJSON(c, status, APIErrorResponse{Error: APIError{
+		Code:      code,
+		Message:   msg,
+		Details:   details,
+		RequestID: c.GetString(requestIDKey),
+	}})
+}
+
+// respondInternalError responds with the status and code of the indexer sentinel wrapped by err,
+// or with a 500 for unexpected errors
+func respondInternalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, indexer.ErrBlockNotFound):
+		respondError(c, http.StatusNotFound, codeBlockNotFound, sentinelMessage(c, err, indexer.ErrBlockNotFound))
+	case errors.Is(err, indexer.ErrBlockPruned):
+		respondError(c, http.StatusGone, codeBlockPruned, sentinelMessage(c, err, indexer.ErrBlockPruned))
+	case errors.Is(err, indexer.ErrRateLimited):
+		c.Header("Retry-After", "1")
+		respondError(c, http.StatusTooManyRequests, codeRateLimited, sentinelMessage(c, err, indexer.ErrRateLimited))
+	case indexer.IsQueryTimeout(err):
+		respondError(c, http.StatusGatewayTimeout, codeTimeout, "the query exceeded the database statement timeout, narrow the range")
+	default:
+		respondUnexpectedError(c, http.StatusInternalServerError, codeInternal, err)
+	}
+}
+
+// sentinelMessage returns the message of the sentinel wrapped by err, without the context wrapping it, which
+// may quote database or RPC errors, unless APP_ENV=development
+func sentinelMessage(c *gin.Context, err, sentinel error) string {
+	if c.GetBool(verboseErrorsKey) {
+		return err.Error()
+	}
+	return sentinel.Error()
+}
+
+// respondUnexpectedError logs err with the request id and responds with a generic message, or with
+// err itself when APP_ENV=development, so database and RPC details don't leak to clients
+func respondUnexpectedError(c *gin.Context, status int, code string, err error) {
+	log.Printf("Request %s %s %s failed: %v", c.GetString(requestIDKey), c.Request.Method, c.Request.URL.Path, err)
+	if c.GetBool(verboseErrorsKey) {
+		respondError(c, status, code, err.Error())
+		return
+	}
+	respondError(c, status, code, http.StatusText(status)+", see the server logs for this request id")
+}
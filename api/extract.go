@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// maxExtractPathLength caps the length of /block/:height/extract path expressions
+const maxExtractPathLength = 256
+
+// validateExtractPath rejects gjson path expressions with empty components, a dangling escape
+// or unbalanced brackets, which gjson would otherwise silently treat as matching nothing
+func validateExtractPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("'path' is required")
+	}
+	if len(path) > maxExtractPathLength {
+		return fmt.Errorf("'path' must not exceed %d characters", maxExtractPathLength)
+	}
+
+	var (
+		open     []rune
+		escaped  bool
+		inString bool
+		previous rune
+	)
+	closers := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	for i, r := range path {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case inString:
+			inString = r != '"'
+		case r == '"' && len(open) > 0:
+			inString = true
+		case r == '(' || r == '[' || r == '{':
+			open = append(open, r)
+		case closers[r] != 0:
+			if len(open) == 0 || open[len(open)-1] != closers[r] {
+				return fmt.Errorf("invalid 'path': unbalanced %q at offset %d", r, i)
+			}
+			open = open[:len(open)-1]
+		case r == '.' && len(open) == 0 && (i == 0 || previous == '.' || previous == '|'):
+			return fmt.Errorf("invalid 'path': empty component at offset %d", i)
+		}
+		previous = r
+	}
+	switch {
+	case escaped:
+		return fmt.Errorf("invalid 'path': dangling escape")
+	case inString:
+		return fmt.Errorf("invalid 'path': unterminated string")
+	case len(open) > 0:
+		return fmt.Errorf("invalid 'path': unclosed %q", open[len(open)-1])
+	case strings.HasSuffix(path, ".") || strings.HasSuffix(path, "|"):
+		return fmt.Errorf("invalid 'path': empty trailing component")
+	}
+
+	return nil
+}
+
+// getBlockExtractHandler handles the /block/:height/extract endpoint, evaluating a gjson
+// path expression against the stored details of a block
+func (a *API) getBlockExtractHandler(c *gin.Context) {
+	height, err := a.parseHeight(c)
+	if err != nil {
+		respondHeightError(c, err)
+		return
+	}
+	path := c.Query("path")
+	if err := validateExtractPath(path); err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	blockDetails, _, err := a.indexer.GetBlockDetails(height)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	if len(blockDetails.Details) == 0 {
+		respondError(c, http.StatusNotFound, codeNotFound, fmt.Sprintf("block %d has no details", height))
+		return
+	}
+
+	result := gjson.GetBytes(blockDetails.Details, path)
+	if !result.Exists() {
+		respondError(c, http.StatusNotFound, codeNotFound, fmt.Sprintf("path %q matches nothing in the details of block %d", path, height))
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"height": height,
+		"path":   path,
+		"value":  json.RawMessage(result.Raw),
+	})
+}
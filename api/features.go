@@ -0,0 +1,41 @@
+package api
+
+import (
+	"log"
+	"strings"
+
+	"github.com/muhammadfarhankt/omniFlix/config"
+)
+
+// Optional features that can be enabled per deployment through FEATURES
+const (
+	featureStats  = "stats"
+	featureExport = "export"
+)
+
+// features holds the set of enabled optional features
+type features map[string]bool
+
+// loadFeatures reads the comma-separated FEATURES env var (e.g. FEATURES=stats,export).
+// When FEATURES is unset every optional feature is enabled.
+func loadFeatures() features {
+	names := config.List("FEATURES")
+	if len(names) == 0 {
+		return nil
+	}
+
+	enabled := features{}
+	for _, name := range names {
+		enabled[strings.ToLower(name)] = true
+	}
+	log.Printf("Enabled features: %s", strings.Join(names, ","))
+	return enabled
+}
+
+// enabled reports whether the named feature should be registered
+func (f features) enabled(name string) bool {
+	if f == nil {
+		return true
+	}
+	return f[name]
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// parseFields reads the optional comma-separated fields query parameter projecting block responses,
+// rejecting names that are not BlockDetails fields. It returns nil when every field is requested.
+func parseFields(c *gin.Context) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(c.Query("fields"), ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		if !indexer.ValidBlockField(field) {
+			return nil, fmt.Errorf("unknown field %q, expected any of %s", field, strings.Join(indexer.BlockFields, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// respondBlock writes blockDetails projected to fields, or whole without fields
+func respondBlock(c *gin.Context, blockDetails indexer.BlockDetails, fields []string) {
+	if fields == nil {
+		respondJSON(c, http.StatusOK, blockDetails)
+		return
+	}
+	projected, err := blockDetails.Project(fields)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	respondJSON(c, http.StatusOK, projected)
+}
+
+// respondBlockFrom writes blockDetails like respondBlock, with a source field telling whether it was
+// read from the database or fetched from the blockchain
+func respondBlockFrom(c *gin.Context, blockDetails indexer.BlockDetails, fields []string, source indexer.BlockSource) {
+	if fields == nil {
+		fields = indexer.BlockFields
+	}
+	projected, err := blockDetails.Project(fields)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	projected["source"], _ = json.Marshal(source)
+	respondJSON(c, http.StatusOK, projected)
+}
+
+// respondBlocks writes blocks projected to fields, or whole without fields
+func respondBlocks(c *gin.Context, blocks []indexer.BlockDetails, fields []string) {
+	if fields == nil {
+		respondJSON(c, http.StatusOK, blocks)
+		return
+	}
+	projected := make([]map[string]json.RawMessage, 0, len(blocks))
+	for _, blockDetails := range blocks {
+		block, err := blockDetails.Project(fields)
+		if err != nil {
+			respondInternalError(c, err)
+			return
+		}
+		projected = append(projected, block)
+	}
+	respondJSON(c, http.StatusOK, projected)
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// maxGraphQLBlocks caps the number of blocks of the GraphQL blocks query, whose blocks may each resolve their events
+const maxGraphQLBlocks = 100
+
+// graphQLRequest is the body of a POST /graphql request
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// jsonScalar passes JSON documents, like event attributes and block details, through as is
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "An arbitrary JSON value",
+	Serialize: func(value interface{}) interface{} {
+		raw, ok := value.(json.RawMessage)
+		if !ok {
+			return value
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return nil
+		}
+		return decoded
+	},
+	ParseValue:   func(value interface{}) interface{} { return value },
+	ParseLiteral: func(valueAST ast.Value) interface{} { return nil },
+})
+
+// graphQLTransaction groups the events of a transaction of a block
+type graphQLTransaction struct {
+	Index  int
+	Events []indexer.BlockEvent
+}
+
+// blockField resolves a Block field from the indexer.BlockDetails source
+func blockField(get func(b indexer.BlockDetails) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(indexer.BlockDetails)), nil
+	}
+}
+
+// newGraphQLSchema builds the GraphQL schema, resolving blocks and their events against the indexer
+func (a *API) newGraphQLSchema() (graphql.Schema, error) {
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Event",
+		Fields: graphql.Fields{
+			"phase": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(indexer.BlockEvent).Phase, nil
+			}},
+			"txIndex": &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if txIndex := p.Source.(indexer.BlockEvent).TxIndex; txIndex != nil {
+					return *txIndex, nil
+				}
+				return nil, nil
+			}},
+			"eventIndex": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(indexer.BlockEvent).EventIndex, nil
+			}},
+			"type": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(indexer.BlockEvent).Type, nil
+			}},
+			"attributes": &graphql.Field{Type: jsonScalar, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(indexer.BlockEvent).Attributes, nil
+			}},
+		},
+	})
+
+	transactionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transaction",
+		Fields: graphql.Fields{
+			"index": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(graphQLTransaction).Index, nil
+			}},
+			"events": &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(eventType)), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(graphQLTransaction).Events, nil
+			}},
+		},
+	})
+
+	blockType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Block",
+		Fields: graphql.Fields{
+			"height":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: blockField(func(b indexer.BlockDetails) interface{} { return b.Height })},
+			"blockId":         &graphql.Field{Type: graphql.String, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return b.BlockID })},
+			"proposer":        &graphql.Field{Type: graphql.String, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return b.Proposer })},
+			"numTransactions": &graphql.Field{Type: graphql.Int, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return b.NumTransactions })},
+			"numEvents":       &graphql.Field{Type: graphql.Int, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return b.NumEvents })},
+			// Gas totals can exceed the 32-bit GraphQL Int
+			"totalGasUsed":   &graphql.Field{Type: graphql.Float, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return float64(b.TotalGasUsed) })},
+			"totalGasWanted": &graphql.Field{Type: graphql.Float, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return float64(b.TotalGasWanted) })},
+			"blockSizeBytes": &graphql.Field{Type: graphql.Int, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return b.BlockSizeBytes })},
+			"appHash":        &graphql.Field{Type: graphql.String, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return b.AppHash })},
+			"txMessageTypes": &graphql.Field{Type: jsonScalar, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return b.TxMessageTypes })},
+			"details":        &graphql.Field{Type: jsonScalar, Resolve: blockField(func(b indexer.BlockDetails) interface{} { return b.Details })},
+			"blockTime": &graphql.Field{Type: graphql.String, Resolve: blockField(func(b indexer.BlockDetails) interface{} {
+				if b.BlockTime.IsZero() {
+					return nil
+				}
+				return b.BlockTime.Format(time.RFC3339Nano)
+			})},
+			"events": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(eventType)),
+				Args: graphql.FieldConfigArgument{"type": &graphql.ArgumentConfig{Type: graphql.String}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					eventType, _ := p.Args["type"].(string)
+					return a.indexer.GetBlockEvents(p.Context, p.Source.(indexer.BlockDetails).Height, eventType)
+				},
+			},
+			"transactions": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(transactionType)),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					blockDetails := p.Source.(indexer.BlockDetails)
+					events, err := a.indexer.GetBlockEvents(p.Context, blockDetails.Height, "")
+					if err != nil {
+						return nil, err
+					}
+					transactions := make([]graphQLTransaction, blockDetails.NumTransactions)
+					for i := range transactions {
+						transactions[i].Index = i
+					}
+					for _, event := range events {
+						if event.TxIndex != nil && *event.TxIndex < len(transactions) {
+							transactions[*event.TxIndex].Events = append(transactions[*event.TxIndex].Events, event)
+						}
+					}
+					return transactions, nil
+				},
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"block": &graphql.Field{
+				Type: blockType,
+				Args: graphql.FieldConfigArgument{"height": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					height := int64(p.Args["height"].(int))
+					if height <= 0 {
+						return nil, fmt.Errorf("block height must be positive")
+					}
+					blockDetails, _, err := a.indexer.GetBlockDetails(height)
+					if errors.Is(err, indexer.ErrBlockNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					return *blockDetails, nil
+				},
+			},
+			"blocks": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(blockType)),
+				Args: graphql.FieldConfigArgument{
+					"proposer":  &graphql.ArgumentConfig{Type: graphql.String},
+					"from":      &graphql.ArgumentConfig{Type: graphql.Int},
+					"to":        &graphql.ArgumentConfig{Type: graphql.Int},
+					"minEvents": &graphql.ArgumentConfig{Type: graphql.Int},
+					"tag":       &graphql.ArgumentConfig{Type: graphql.String},
+					"sort":      &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: indexer.SortHeightDesc},
+					"limit":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit},
+					"offset":    &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: a.resolveGraphQLBlocks,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// resolveGraphQLBlocks resolves the blocks query, validating its arguments like /blocks
+func (a *API) resolveGraphQLBlocks(p graphql.ResolveParams) (interface{}, error) {
+	var filter indexer.BlockFilter
+	if proposer, _ := p.Args["proposer"].(string); proposer != "" {
+		normalized, err := indexer.NormalizeAddress(proposer)
+		if err != nil {
+			return nil, err
+		}
+		filter.Proposer = normalized
+	}
+	if from, ok := p.Args["from"].(int); ok {
+		filter.From = int64(from)
+	}
+	if to, ok := p.Args["to"].(int); ok {
+		filter.To = int64(to)
+	}
+	if minEvents, ok := p.Args["minEvents"].(int); ok {
+		filter.MinEvents = minEvents
+	}
+	filter.Tag, _ = p.Args["tag"].(string)
+	if filter.From < 0 || filter.To < 0 || filter.MinEvents < 0 {
+		return nil, fmt.Errorf("'from', 'to' and 'minEvents' must not be negative")
+	}
+	if filter.From > 0 && filter.To > 0 && filter.From > filter.To {
+		return nil, fmt.Errorf("'from' must not be greater than 'to'")
+	}
+
+	sort := p.Args["sort"].(string)
+	if !indexer.ValidBlockSort(sort) {
+		return nil, fmt.Errorf("invalid 'sort', expected one of height_asc, height_desc, txs_desc, time_desc")
+	}
+	limit, offset := p.Args["limit"].(int), p.Args["offset"].(int)
+	if limit <= 0 || offset < 0 {
+		return nil, fmt.Errorf("'limit' must be positive and 'offset' must not be negative")
+	}
+	if limit > maxGraphQLBlocks {
+		limit = maxGraphQLBlocks
+	}
+
+	return a.indexer.ListBlocks(filter, sort, limit, offset, nil)
+}
+
+// graphQLHandler handles the /graphql endpoint, taking the query from a JSON body (POST) or the
+// query/variables/operationName parameters (GET)
+func (a *API) graphQLHandler(c *gin.Context) {
+	var request graphQLRequest
+	if c.Request.Method == http.MethodPost {
+		if err := bindJSON(c, &request); err != nil {
+			respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+			return
+		}
+	} else {
+		request.Query = c.Query("query")
+		request.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'variables'")
+				return
+			}
+		}
+	}
+	if request.Query == "" {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "'query' is required")
+		return
+	}
+
+	// Errors of individual fields are reported in the "errors" array of the result, per the GraphQL spec
+	result := graphql.Do(graphql.Params{
+		Schema:         a.graphQLSchema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        c.Request.Context(),
+	})
+	respondJSON(c, http.StatusOK, result)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readyPingTimeout bounds the database ping of /ready
+const readyPingTimeout = 2 * time.Second
+
+// healthHandler handles the /health liveness endpoint
+func (a *API) healthHandler(c *gin.Context) {
+	respondJSON(c, http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyHandler handles the /ready endpoint, reporting unhealthy when the database is unreachable
+// or indexing stalled for READY_STALL_WINDOW while the chain advanced
+func (a *API) readyHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyPingTimeout)
+	defer cancel()
+
+	if err := a.indexer.Ping(ctx); err != nil {
+		respondUnexpectedError(c, http.StatusServiceUnavailable, codeUnavailable, err)
+		return
+	}
+	if err := a.indexer.CheckProgress(a.stallWindow); err != nil {
+		respondError(c, http.StatusServiceUnavailable, codeUnavailable, err.Error())
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{"status": "ready"})
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muhammadfarhankt/omniFlix/config"
+)
+
+// defaultMaxBodyBytes is the default limit for request bodies (1MB)
+const defaultMaxBodyBytes = 1 << 20
+
+// maxBodySize limits the size of request bodies to n bytes
+func maxBodySize(n int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+		}
+		c.Next()
+	}
+}
+
+// probePaths are the health and monitoring endpoints exempt from the in-flight limit
+var probePaths = map[string]bool{"/health": true, "/ready": true, "/metrics": true}
+
+// maxInFlight rejects requests with 503 once n requests are being served, protecting the database
+// from bursts of expensive queries. Probes are exempt; n <= 0 disables the limit.
+func maxInFlight(n int) gin.HandlerFunc {
+	if n <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	slots := make(chan struct{}, n)
+	return func(c *gin.Context) {
+		if probePaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			c.Next()
+		default:
+			c.Header("Retry-After", "1")
+			respondError(c, http.StatusServiceUnavailable, codeUnavailable, "too many requests in flight, retry later")
+		}
+	}
+}
+
+// validator is implemented by request bodies that check their own fields after decoding
+type validator interface {
+	Validate() error
+}
+
+// bindJSON strictly decodes the JSON request body into v, rejecting unknown fields
+// and trailing data, and validates it when v implements validator.
+// The returned error message is safe to return to clients.
+func bindJSON(c *gin.Context, v interface{}) error {
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			return fmt.Errorf("request body exceeds %d bytes", maxBytesErr.Limit)
+		case errors.Is(err, io.EOF):
+			return fmt.Errorf("request body must not be empty")
+		case errors.As(err, &syntaxErr):
+			return fmt.Errorf("malformed JSON at position %d", syntaxErr.Offset)
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return fmt.Errorf("malformed JSON")
+		case errors.As(err, &typeErr):
+			return fmt.Errorf("invalid value for field %q", typeErr.Field)
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			return fmt.Errorf("unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+		default:
+			return fmt.Errorf("invalid request body")
+		}
+	}
+	if decoder.More() {
+		return fmt.Errorf("request body must contain a single JSON object")
+	}
+
+	if v, ok := v.(validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// corsConfig holds the CORS policy read from the environment
+type corsConfig struct {
+	origins          map[string]bool
+	allowAll         bool
+	allowCredentials bool
+	maxAge           time.Duration
+}
+
+// loadCORSConfig reads CORS_ALLOWED_ORIGINS (comma-separated, "*" for any origin; default none),
+// CORS_ALLOW_CREDENTIALS and CORS_MAX_AGE
+func loadCORSConfig() corsConfig {
+	cfg := corsConfig{
+		origins:          map[string]bool{},
+		allowCredentials: config.Bool("CORS_ALLOW_CREDENTIALS", false),
+		maxAge:           config.Duration("CORS_MAX_AGE", 10*time.Minute),
+	}
+	for _, origin := range config.List("CORS_ALLOWED_ORIGINS") {
+		if origin == "*" {
+			cfg.allowAll = true
+			continue
+		}
+		cfg.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	return cfg
+}
+
+// cors adds CORS headers for allowed origins and answers preflight requests
+func cors(cfg corsConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || !(cfg.allowAll || cfg.origins[origin]) {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		// A wildcard is not valid for credentialed requests, so the origin is echoed instead
+		if cfg.allowAll && !cfg.allowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.allowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		header.Set("Access-Control-Expose-Headers", "X-Request-ID, X-Total-Count, X-Total-Count-Estimated, Link")
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.maxAge.Seconds())))
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// verboseErrorsKey is the gin context key set when unexpected errors are returned verbatim
+const verboseErrorsKey = "verbose_errors"
+
+// errorVerbosity marks every request to return unexpected errors verbatim when verbose is set
+func errorVerbosity(verbose bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(verboseErrorsKey, verbose)
+		c.Next()
+	}
+}
+
+// requestIDKey is the gin context key holding the request id
+const requestIDKey = "request_id"
+
+// requestID tags each request with the client's X-Request-ID, or a generated one, and echoes it in the response
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			b := make([]byte, 8)
+			if _, err := rand.Read(b); err == nil {
+				id = hex.EncodeToString(b)
+			}
+		}
+		c.Set(requestIDKey, id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
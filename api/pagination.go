@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setPaginationHeaders sets X-Total-Count and an RFC 5988 Link header with the first, prev, next
+// and last pages of a limit/offset list, so generic clients can paginate without parsing the body
+func setPaginationHeaders(c *gin.Context, limit, offset int, total int64) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	pageLink := func(offset int, rel string) string {
+		u := *c.Request.URL
+		query := u.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+		u.RawQuery = query.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+	}
+
+	links := []string{pageLink(0, "first")}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink(prev, "prev"))
+	}
+	if int64(offset+limit) < total {
+		links = append(links, pageLink(offset+limit, "next"))
+	}
+	last := 0
+	if total > 0 {
+		last = int((total - 1) / int64(limit) * int64(limit))
+	}
+	links = append(links, pageLink(last, "last"))
+
+	c.Header("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// maxTimelineSpan caps the height range of /proposer/:address/timeline
+const maxTimelineSpan = 100000
+
+// Minutes bounds for /proposer/:address/recent
+const (
+	defaultRecentMinutes = 60
+	maxRecentMinutes     = 24 * 60
+)
+
+// maxSequenceSpan caps the height range of /proposers/sequence
+const maxSequenceSpan = 10000
+
+// maxProposerAddresses caps the number of addresses of /proposers/blocks
+const maxProposerAddresses = 50
+
+// getProposerTimelineHandler handles the /proposer/:address/timeline endpoint
+func (a *API) getProposerTimelineHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	if to-from+1 > maxTimelineSpan {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("range must not exceed %d blocks", maxTimelineSpan))
+		return
+	}
+
+	address, err := indexer.NormalizeAddress(c.Param("address"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	timeline, err := a.indexer.GetProposerTimeline(address, from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"proposer": address,
+		"from":     from,
+		"to":       to,
+		"blocks":   timeline,
+	})
+}
+
+// getProposerRecentHandler handles the /proposer/:address/recent endpoint
+func (a *API) getProposerRecentHandler(c *gin.Context) {
+	minutes, err := strconv.Atoi(c.DefaultQuery("minutes", strconv.Itoa(defaultRecentMinutes)))
+	if err != nil || minutes <= 0 || minutes > maxRecentMinutes {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("'minutes' must be between 1 and %d", maxRecentMinutes))
+		return
+	}
+
+	address, err := indexer.NormalizeAddress(c.Param("address"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	since := time.Now().UTC().Add(-time.Duration(minutes) * time.Minute)
+	blocks, err := a.indexer.GetProposerRecent(address, since)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"proposer": address,
+		"minutes":  minutes,
+		"since":    since,
+		"blocks":   blocks,
+	})
+}
+
+// getProposersHandler handles the /proposers endpoint
+func (a *API) getProposersHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	proposers, err := a.indexer.GetActiveProposers(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"from":      from,
+		"to":        to,
+		"count":     len(proposers),
+		"proposers": proposers,
+	})
+}
+
+// getProposerSequenceHandler handles the /proposers/sequence endpoint
+func (a *API) getProposerSequenceHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	if to-from+1 > maxSequenceSpan {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("range must not exceed %d blocks", maxSequenceSpan))
+		return
+	}
+
+	sequence, err := a.indexer.GetProposerSequence(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, sequence)
+}
+
+// getProposersBlocksHandler handles the /proposers/blocks endpoint
+func (a *API) getProposersBlocksHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	var addresses []string
+	for _, address := range strings.Split(c.Query("addresses"), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			normalized, err := indexer.NormalizeAddress(address)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+				return
+			}
+			addresses = append(addresses, normalized)
+		}
+	}
+	if len(addresses) == 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "'addresses' is required")
+		return
+	}
+	if len(addresses) > maxProposerAddresses {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("at most %d addresses are allowed", maxProposerAddresses))
+		return
+	}
+
+	fields, err := parseFields(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	blocks, err := a.indexer.GetBlocksByProposers(addresses, from, to, limit, offset, fields)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	total, err := a.indexer.CountBlocksByProposers(addresses, from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	setPaginationHeaders(c, limit, offset, total)
+
+	respondBlocks(c, blocks, fields)
+}
+
+// getValidatorBlocksHandler handles the /validators/:valoper/blocks endpoint, listing the blocks of the validator
+// like /blocks?proposer= does for its consensus address
+func (a *API) getValidatorBlocksHandler(c *gin.Context) {
+	filter, err := parseBlockFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	valoper := c.Param("valoper")
+	address, err := a.indexer.ConsensusAddress(valoper)
+	if errors.Is(err, indexer.ErrValidatorNotFound) {
+		respondError(c, http.StatusNotFound, codeNotFound, fmt.Sprintf("validator %s not found", valoper))
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	filter.Proposer = address
+
+	a.respondBlockList(c, filter)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRangeSpan caps the height range of /blocks/range
+const maxRangeSpan = 1000
+
+// getBlocksRangeHandler handles the /blocks/range endpoint
+func (a *API) getBlocksRangeHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	if to-from+1 > maxRangeSpan {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("range must not exceed %d blocks", maxRangeSpan))
+		return
+	}
+	fetch, err := strconv.ParseBool(c.DefaultQuery("fetch", "false"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "invalid 'fetch'")
+		return
+	}
+
+	result, err := a.indexer.GetRange(from, to, fetch, a.maxRangeFetches)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"from":    from,
+		"to":      to,
+		"blocks":  result.Blocks,
+		"fetched": result.Fetched,
+		"missing": result.Missing,
+	})
+}
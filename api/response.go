@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondJSON writes obj as the JSON response body, indented when a GET request sets ?pretty=true
+// for human inspection, compact otherwise
+func respondJSON(c *gin.Context, status int, obj interface{}) {
+	if c.Request.Method == http.MethodGet && c.Query("pretty") == "true" {
+		c.IndentedJSON(status, obj)
+		return
+	}
+	c.JSON(status, obj)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDetailsPathDepth caps the number of segments of the /blocks/search-details path
+const maxDetailsPathDepth = 16
+
+// detailsFragment builds the JSON fragment matched against the details from a dot-separated path
+// and a value. A segment ending in "[]" matches any element of an array, e.g. the path
+// "txs_results[].events[].type" and value "transfer" build
+// {"txs_results":[{"events":[{"type":"transfer"}]}]}.
+// The value is used as is when it is valid JSON and as a string otherwise.
+func detailsFragment(path, value string) (json.RawMessage, error) {
+	if path == "" {
+		return nil, fmt.Errorf("'path' is required")
+	}
+	segments := strings.Split(path, ".")
+	if len(segments) > maxDetailsPathDepth {
+		return nil, fmt.Errorf("'path' must not have more than %d segments", maxDetailsPathDepth)
+	}
+
+	var fragment interface{} = value
+	if json.Valid([]byte(value)) {
+		fragment = json.RawMessage(value)
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		key, isArray := strings.CutSuffix(segments[i], "[]")
+		if isArray {
+			fragment = []interface{}{fragment}
+		}
+		if key == "" {
+			if !isArray || i > 0 {
+				return nil, fmt.Errorf("invalid 'path'")
+			}
+			// A leading "[]" matches a top-level array
+			continue
+		}
+		fragment = map[string]interface{}{key: fragment}
+	}
+
+	return json.Marshal(fragment)
+}
+
+// getSearchDetailsHandler handles the /blocks/search-details endpoint
+func (a *API) getSearchDetailsHandler(c *gin.Context) {
+	fragment, err := detailsFragment(c.Query("path"), c.Query("value"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	fields, err := parseFields(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	blocks, err := a.indexer.SearchDetails(fragment, limit, offset, fields)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	total, err := a.indexer.CountDetailsMatches(fragment)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	setPaginationHeaders(c, limit, offset, total)
+
+	respondBlocks(c, blocks, fields)
+}
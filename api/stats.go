@@ -0,0 +1,340 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// Page size bounds for list endpoints
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// defaultHistogramBuckets are the transaction count upper bounds of /stats/tx-histogram buckets
+var defaultHistogramBuckets = []int64{0, 10, 50, 100}
+
+// maxHistogramBuckets caps the number of buckets of /stats/tx-histogram
+const maxHistogramBuckets = 50
+
+// Window bounds for /stats/participation
+const (
+	defaultParticipationWindow = 100
+	maxParticipationWindow     = 10000
+)
+
+// parseRange reads the required from/to query parameters of range endpoints
+func parseRange(c *gin.Context) (int64, int64, error) {
+	from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid 'from' height")
+	}
+	to, err := strconv.ParseInt(c.Query("to"), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid 'to' height")
+	}
+	if from <= 0 || to <= 0 {
+		return 0, 0, fmt.Errorf("heights must be positive")
+	}
+	if from > to {
+		return 0, 0, fmt.Errorf("'from' must not be greater than 'to'")
+	}
+
+	return from, to, nil
+}
+
+// parseBlockFilter reads the optional proposer/from/to/min_events/tag query parameters of block list endpoints
+func parseBlockFilter(c *gin.Context) (indexer.BlockFilter, error) {
+	var (
+		filter = indexer.BlockFilter{Tag: c.Query("tag")}
+		err    error
+	)
+	if proposer := c.Query("proposer"); proposer != "" {
+		if filter.Proposer, err = indexer.NormalizeAddress(proposer); err != nil {
+			return filter, err
+		}
+	}
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = strconv.ParseInt(from, 10, 64); err != nil || filter.From <= 0 {
+			return filter, fmt.Errorf("invalid 'from' height")
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = strconv.ParseInt(to, 10, 64); err != nil || filter.To <= 0 {
+			return filter, fmt.Errorf("invalid 'to' height")
+		}
+	}
+	if minEvents := c.Query("min_events"); minEvents != "" {
+		if filter.MinEvents, err = strconv.Atoi(minEvents); err != nil || filter.MinEvents < 0 {
+			return filter, fmt.Errorf("invalid 'min_events'")
+		}
+	}
+	if filter.From > 0 && filter.To > 0 && filter.From > filter.To {
+		return filter, fmt.Errorf("'from' must not be greater than 'to'")
+	}
+
+	return filter, nil
+}
+
+// parsePagination reads the optional limit/offset query parameters of list endpoints
+func parsePagination(c *gin.Context) (int, int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 {
+		return 0, 0, fmt.Errorf("invalid 'limit'")
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, fmt.Errorf("invalid 'offset'")
+	}
+
+	return limit, offset, nil
+}
+
+// getSizeStatsHandler handles the /stats/size endpoint
+func (a *API) getSizeStatsHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	stats, err := a.indexer.GetSizeStats(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, stats)
+}
+
+// getGasStatsHandler handles the /stats/gas endpoint
+func (a *API) getGasStatsHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	stats, err := a.indexer.GetGasStats(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, stats)
+}
+
+// getHourlyStatsHandler handles the /stats/hourly endpoint
+func (a *API) getHourlyStatsHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	distribution, err := a.indexer.GetHourlyDistribution(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"from":     from,
+		"to":       to,
+		"timezone": "UTC",
+		"hours":    distribution,
+	})
+}
+
+// defaultSlowBlockThreshold is the block interval, in seconds, above which /stats/slow-blocks reports a block
+const defaultSlowBlockThreshold = 30
+
+// getSlowBlocksHandler handles the /stats/slow-blocks endpoint
+func (a *API) getSlowBlocksHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", strconv.Itoa(defaultSlowBlockThreshold)), 64)
+	if err != nil || !(threshold > 0) || math.IsInf(threshold, 1) {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "'threshold' must be a positive number of seconds")
+		return
+	}
+
+	slow, err := a.indexer.GetSlowBlocks(from, to, threshold)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"from":              from,
+		"to":                to,
+		"threshold_seconds": threshold,
+		"blocks":            slow,
+	})
+}
+
+// getProposerStatsHandler handles the /stats/proposers endpoint
+func (a *API) getProposerStatsHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	stats, err := a.indexer.GetProposerStats(from, to, limit, offset)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	total, err := a.indexer.CountProposers(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	setPaginationHeaders(c, limit, offset, total)
+
+	respondJSON(c, http.StatusOK, stats)
+}
+
+// getProposersLastSeenHandler handles the /stats/proposers/last-seen endpoint
+func (a *API) getProposersLastSeenHandler(c *gin.Context) {
+	lastSeen, err := a.indexer.GetProposersLastSeen()
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, lastSeen)
+}
+
+// getProposerStreaksHandler handles the /stats/proposer-streaks endpoint
+func (a *API) getProposerStreaksHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	streaks, err := a.indexer.GetProposerStreaks(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, streaks)
+}
+
+// getDecentralizationHandler handles the /stats/decentralization endpoint
+func (a *API) getDecentralizationHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	dist, err := a.indexer.GetProposerDistribution(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, dist)
+}
+
+// getTxTypeStatsHandler handles the /stats/tx-types endpoint
+func (a *API) getTxTypeStatsHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	counts, err := a.indexer.GetTxTypeStats(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, counts)
+}
+
+// getParticipationHandler handles the /stats/participation endpoint
+func (a *API) getParticipationHandler(c *gin.Context) {
+	window, err := strconv.Atoi(c.DefaultQuery("window", strconv.Itoa(defaultParticipationWindow)))
+	if err != nil || window <= 0 || window > maxParticipationWindow {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("'window' must be between 1 and %d", maxParticipationWindow))
+		return
+	}
+
+	participation, err := a.indexer.GetParticipation(window)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, participation)
+}
+
+// parseBuckets reads the comma-separated ascending bucket upper bounds of the buckets query parameter
+func parseBuckets(c *gin.Context) ([]int64, error) {
+	param := c.Query("buckets")
+	if param == "" {
+		return defaultHistogramBuckets, nil
+	}
+
+	parts := strings.Split(param, ",")
+	if len(parts) > maxHistogramBuckets {
+		return nil, fmt.Errorf("at most %d buckets are allowed", maxHistogramBuckets)
+	}
+	bounds := make([]int64, 0, len(parts))
+	for _, part := range parts {
+		bound, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || bound < 0 {
+			return nil, fmt.Errorf("invalid bucket bound %q", part)
+		}
+		if len(bounds) > 0 && bound <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("bucket bounds must be strictly ascending")
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds, nil
+}
+
+// getTxHistogramHandler handles the /stats/tx-histogram endpoint
+func (a *API) getTxHistogramHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	bounds, err := parseBuckets(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	buckets, err := a.indexer.GetTxHistogram(from, to, bounds)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, buckets)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// getStatusHandler handles the /status endpoint, summarizing the indexed data
+func (a *API) getStatusHandler(c *gin.Context) {
+	total, exact, err := a.indexer.TotalBlocks(c.Query("exact") == "true")
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	var latestIndexed int64
+	latest, err := a.indexer.GetLatestIndexedBlock()
+	switch {
+	case err == nil:
+		latestIndexed = latest.Height
+	case !errors.Is(err, indexer.ErrBlockNotFound):
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"chain_height":          a.indexer.ChainHeight(),
+		"latest_indexed_height": latestIndexed,
+		"total_blocks":          total,
+		"total_blocks_exact":    exact,
+		"rpc_endpoints":         a.indexer.RPCEndpoints(),
+	})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// BlockStore is what the API needs from the indexer. *indexer.Indexer implements it; handlers
+// depend on the interface so they can be served from another backend, like an in-memory store.
+type BlockStore interface {
+	// Blocks
+	GetBlockDetails(height int64) (*indexer.BlockDetails, indexer.BlockSource, error)
+	RefreshBlockDetails(height int64) (indexer.BlockDetails, error)
+	GetEarliestIndexedBlock() (*indexer.BlockDetails, error)
+	GetLatestIndexedBlock() (*indexer.BlockDetails, error)
+	GetLatestBlocks(n int) ([]indexer.BlockDetails, error)
+	ResolveTipOffset(offset int64) (int64, error)
+	VerifyBlock(height int64) (*indexer.BlockVerification, error)
+	GetBlockEvents(ctx context.Context, height int64, eventType string) ([]indexer.BlockEvent, error)
+	GetRange(from, to int64, fetch bool, maxFetches int) (*indexer.RangeBlocks, error)
+	GetMissingRanges(from, to int64) ([]indexer.HeightRange, error)
+	GetRangeChecksum(ctx context.Context, from, to int64) (*indexer.RangeChecksum, error)
+
+	// Listing and search
+	ListBlocks(filter indexer.BlockFilter, sort string, limit, offset int, fields []string) ([]indexer.BlockDetails, error)
+	CountBlocks(filter indexer.BlockFilter) (int64, error)
+	TotalBlocks(exact bool) (int64, bool, error)
+	StreamBlocks(ctx context.Context, from, to int64, fn func(indexer.BlockDetails) error) error
+	SearchDetails(fragment json.RawMessage, limit, offset int, fields []string) ([]indexer.BlockDetails, error)
+	CountDetailsMatches(fragment json.RawMessage) (int64, error)
+	GetBlocksByProposers(addresses []string, from, to int64, limit, offset int, fields []string) ([]indexer.BlockDetails, error)
+	CountBlocksByProposers(addresses []string, from, to int64) (int64, error)
+
+	// Stats
+	GetProposerStats(from, to int64, limit, offset int) ([]indexer.ProposerStats, error)
+	CountProposers(from, to int64) (int64, error)
+	GetProposerDistribution(from, to int64) (*indexer.ProposerDistribution, error)
+	GetProposerSequence(from, to int64) ([]indexer.ProposerTurn, error)
+	GetActiveProposers(from, to int64) ([]indexer.ProposerStats, error)
+	GetProposerTimeline(address string, from, to int64) ([]indexer.ProposedBlock, error)
+	GetProposerRecent(address string, since time.Time) ([]indexer.ProposedBlock, error)
+	ConsensusAddress(valoper string) (string, error)
+	GetProposersLastSeen() ([]indexer.ProposerLastSeen, error)
+	GetProposerStreaks(from, to int64) ([]indexer.ProposerStreak, error)
+	GetProposerRun(height int64, limit int) (*indexer.ProposerRun, error)
+	GetParticipation(window int) (*indexer.Participation, error)
+	GetGasStats(from, to int64) (*indexer.GasStats, error)
+	GetSizeStats(from, to int64) (*indexer.SizeStats, error)
+	GetTxTypeStats(from, to int64) ([]indexer.TxTypeCount, error)
+	GetTxHistogram(from, to int64, bounds []int64) ([]indexer.HistogramBucket, error)
+	GetHourlyDistribution(from, to int64) ([]indexer.HourlyCount, error)
+	GetSlowBlocks(from, to int64, threshold float64) ([]indexer.SlowBlock, error)
+
+	// Status and health
+	Config() indexer.Config
+	ChainID() (string, error)
+	ChainHeight() int64
+	GetProgress() (*indexer.Progress, error)
+	CheckProgress(window time.Duration) error
+	RPCEndpoints() []indexer.EndpointHealth
+	Ping(ctx context.Context) error
+
+	// Writes and administration
+	UpsertManualBlock(ctx context.Context, blockDetails indexer.BlockDetails) (*indexer.BlockDetails, error)
+	AddBlockTags(ctx context.Context, height int64, tags []string) ([]string, error)
+	BackfillDetails(ctx context.Context, from int64, limit int) (indexer.DetailsBackfill, error)
+	CompactDetails(ctx context.Context, before int64) (int64, error)
+	MaintainBlocks(ctx context.Context, vacuum bool) (*indexer.Maintenance, error)
+	Reset() error
+}
+
+var _ BlockStore = (*indexer.Indexer)(nil)
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// fakeStore is an in-memory BlockStore serving the indexed blocks it holds and, as if fetched from the
+// blockchain, the blocks of chain. Methods it does not implement panic through the nil embedded BlockStore.
+type fakeStore struct {
+	BlockStore
+
+	mu sync.Mutex
+	// blocks are the indexed blocks, by height
+	blocks map[int64]indexer.BlockDetails
+	// chain holds the blocks GetBlockDetails fetches when they are not indexed
+	chain map[int64]indexer.BlockDetails
+	// errs are returned by GetBlockDetails for their heights instead of a block
+	errs map[int64]error
+	// err, when set, fails every listing and count
+	err         error
+	chainHeight int64
+	// calls counts the calls of each method
+	calls map[string]int
+	// runLimit is the limit of the last GetProposerRun call
+	runLimit int
+}
+
+// newFakeStore returns a fakeStore with blocks indexed
+func newFakeStore(blocks ...indexer.BlockDetails) *fakeStore {
+	store := &fakeStore{
+		blocks: map[int64]indexer.BlockDetails{},
+		chain:  map[int64]indexer.BlockDetails{},
+		errs:   map[int64]error{},
+		calls:  map[string]int{},
+	}
+	for _, block := range blocks {
+		store.blocks[block.Height] = block
+	}
+	return store
+}
+
+func (s *fakeStore) called(method string) {
+	s.calls[method]++
+}
+
+func (s *fakeStore) GetBlockDetails(height int64) (*indexer.BlockDetails, indexer.BlockSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.called("GetBlockDetails")
+
+	if err, ok := s.errs[height]; ok {
+		return nil, "", err
+	}
+	if block, ok := s.blocks[height]; ok {
+		return &block, indexer.SourceDB, nil
+	}
+	if block, ok := s.chain[height]; ok {
+		s.blocks[height] = block
+		return &block, indexer.SourceRPC, nil
+	}
+	return nil, "", fmt.Errorf("error fetching and storing block details: %w", indexer.ErrBlockNotFound)
+}
+
+func (s *fakeStore) RefreshBlockDetails(height int64) (indexer.BlockDetails, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.called("RefreshBlockDetails")
+
+	block, ok := s.chain[height]
+	if !ok {
+		return indexer.BlockDetails{}, fmt.Errorf("error getting block details: %w", indexer.ErrBlockNotFound)
+	}
+	s.blocks[height] = block
+	return block, nil
+}
+
+func (s *fakeStore) GetEarliestIndexedBlock() (*indexer.BlockDetails, error) {
+	return s.edgeBlock(false)
+}
+
+func (s *fakeStore) GetLatestIndexedBlock() (*indexer.BlockDetails, error) {
+	return s.edgeBlock(true)
+}
+
+func (s *fakeStore) edgeBlock(latest bool) (*indexer.BlockDetails, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	heights := s.heights()
+	if len(heights) == 0 {
+		return nil, indexer.ErrBlockNotFound
+	}
+	height := heights[0]
+	if latest {
+		height = heights[len(heights)-1]
+	}
+	block := s.blocks[height]
+	return &block, nil
+}
+
+func (s *fakeStore) ResolveTipOffset(offset int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.called("ResolveTipOffset")
+
+	heights := s.heights()
+	if len(heights) == 0 {
+		return 0, fmt.Errorf("%w: no blocks are indexed", indexer.ErrBlockNotFound)
+	}
+	height := heights[len(heights)-1] - offset
+	if height < heights[0] {
+		return 0, fmt.Errorf("%w: offset %d is below the lowest indexed block", indexer.ErrBlockNotFound, offset)
+	}
+	return height, nil
+}
+
+func (s *fakeStore) GetLatestBlocks(n int) ([]indexer.BlockDetails, error) {
+	return s.ListBlocks(indexer.BlockFilter{}, indexer.SortHeightDesc, n, 0, nil)
+}
+
+func (s *fakeStore) ListBlocks(filter indexer.BlockFilter, sortOrder string, limit, offset int, fields []string) ([]indexer.BlockDetails, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.called("ListBlocks")
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	blocks := s.matching(filter)
+	switch sortOrder {
+	case indexer.SortHeightAsc:
+	case indexer.SortHeightDesc:
+		for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+			blocks[i], blocks[j] = blocks[j], blocks[i]
+		}
+	default:
+		return nil, fmt.Errorf("unknown sort order %q", sortOrder)
+	}
+
+	if offset > len(blocks) {
+		offset = len(blocks)
+	}
+	blocks = blocks[offset:]
+	if limit < len(blocks) {
+		blocks = blocks[:limit]
+	}
+	return blocks, nil
+}
+
+func (s *fakeStore) CountBlocks(filter indexer.BlockFilter) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, s.err
+	}
+	return int64(len(s.matching(filter))), nil
+}
+
+func (s *fakeStore) TotalBlocks(exact bool) (int64, bool, error) {
+	count, err := s.CountBlocks(indexer.BlockFilter{})
+	return count, true, err
+}
+
+func (s *fakeStore) GetProposerRun(height int64, limit int) (*indexer.ProposerRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.called("GetProposerRun")
+	s.runLimit = limit
+
+	block, ok := s.blocks[height]
+	if !ok {
+		return nil, fmt.Errorf("%w: block %d is not indexed", indexer.ErrBlockNotFound, height)
+	}
+	run := &indexer.ProposerRun{Height: height, Proposer: block.Proposer, From: height, To: height}
+	for run.From > height-int64(limit) && s.proposed(run.From-1, block.Proposer) {
+		run.From--
+	}
+	for run.To < height+int64(limit) && s.proposed(run.To+1, block.Proposer) {
+		run.To++
+	}
+	run.Length = run.To - run.From + 1
+	run.Truncated = run.From == height-int64(limit) || run.To == height+int64(limit)
+	return run, nil
+}
+
+func (s *fakeStore) GetProposerStats(from, to int64, limit, offset int) ([]indexer.ProposerStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.proposerStats(from, to)
+	if offset > len(stats) {
+		offset = len(stats)
+	}
+	stats = stats[offset:]
+	if limit < len(stats) {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+func (s *fakeStore) CountProposers(from, to int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.proposerStats(from, to))), nil
+}
+
+func (s *fakeStore) ChainHeight() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.chainHeight
+}
+
+func (s *fakeStore) Config() indexer.Config {
+	return indexer.Config{}
+}
+
+// matching returns the indexed blocks matching filter in ascending height order; tags are not supported
+func (s *fakeStore) matching(filter indexer.BlockFilter) []indexer.BlockDetails {
+	var blocks []indexer.BlockDetails
+	for _, height := range s.heights() {
+		block := s.blocks[height]
+		if (filter.Proposer != "" && block.Proposer != filter.Proposer) ||
+			(filter.From > 0 && block.Height < filter.From) ||
+			(filter.To > 0 && block.Height > filter.To) ||
+			block.NumEvents < filter.MinEvents {
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// proposerStats returns the block count per proposer between from and to, ordered like GetProposerStats
+func (s *fakeStore) proposerStats(from, to int64) []indexer.ProposerStats {
+	counts := map[string]int64{}
+	for _, block := range s.matching(indexer.BlockFilter{From: from, To: to}) {
+		if block.Proposer != "" {
+			counts[block.Proposer]++
+		}
+	}
+	stats := []indexer.ProposerStats{}
+	for proposer, blocks := range counts {
+		stats = append(stats, indexer.ProposerStats{Proposer: proposer, Blocks: blocks})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Blocks != stats[j].Blocks {
+			return stats[i].Blocks > stats[j].Blocks
+		}
+		return stats[i].Proposer < stats[j].Proposer
+	})
+	return stats
+}
+
+// proposed reports whether the block at height is indexed and was proposed by proposer
+func (s *fakeStore) proposed(height int64, proposer string) bool {
+	block, ok := s.blocks[height]
+	return ok && block.Proposer == proposer
+}
+
+// heights returns the indexed heights in ascending order
+func (s *fakeStore) heights() []int64 {
+	heights := make([]int64, 0, len(s.blocks))
+	for height := range s.blocks {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
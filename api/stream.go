@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// streamFlushEvery is the number of NDJSON lines written between flushes
+const streamFlushEvery = 100
+
+// getBlocksRangeNDJSONHandler handles the /blocks/range.ndjson endpoint
+func (a *API) getBlocksRangeNDJSONHandler(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	written := 0
+	err = a.indexer.StreamBlocks(c.Request.Context(), from, to, func(blockDetails indexer.BlockDetails) error {
+		// Encode terminates every object with a newline
+		if err := encoder.Encode(blockDetails); err != nil {
+			return err
+		}
+		written++
+		if written%streamFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, so the error can only be logged
+		log.Printf("Error streaming blocks %d-%d: %v", from, to, err)
+	}
+	c.Writer.Flush()
+}
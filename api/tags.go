@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTagsPerRequest caps the number of tags of POST /block/:height/tags
+const maxTagsPerRequest = 20
+
+// tagPattern restricts tags to short lower case slugs such as "upgrade-height"
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// blockTagsRequest is the body of POST /block/:height/tags
+type blockTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// Validate checks the number and the format of the tags
+func (r *blockTagsRequest) Validate() error {
+	if len(r.Tags) == 0 {
+		return fmt.Errorf("tags is required")
+	}
+	if len(r.Tags) > maxTagsPerRequest {
+		return fmt.Errorf("at most %d tags are allowed", maxTagsPerRequest)
+	}
+	for _, tag := range r.Tags {
+		if !tagPattern.MatchString(tag) {
+			return fmt.Errorf("invalid tag %q: tags are 1-64 lower case letters, digits, '.', '_' or '-'", tag)
+		}
+	}
+	return nil
+}
+
+// postBlockTagsHandler handles the POST /block/:height/tags endpoint
+func (a *API) postBlockTagsHandler(c *gin.Context) {
+	height, err := a.parseHeight(c)
+	if err != nil {
+		respondHeightError(c, err)
+		return
+	}
+
+	var req blockTagsRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	tags, err := a.indexer.AddBlockTags(c.Request.Context(), height, req.Tags)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{"height": height, "tags": tags})
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+)
+
+// blockUpdateRequest is the body of PUT /block/:height
+type blockUpdateRequest struct {
+	Height          int64           `json:"height"`
+	BlockID         string          `json:"block_id"`
+	Proposer        string          `json:"proposer"`
+	BlockTime       *time.Time      `json:"block_time"`
+	NumTransactions *int            `json:"num_transactions"`
+	BlockSizeBytes  int64           `json:"block_size_bytes"`
+	AppHash         string          `json:"app_hash"`
+	TxMessageTypes  map[string]int  `json:"tx_message_types"`
+	Details         json.RawMessage `json:"details"`
+}
+
+// Validate checks the required fields of the corrected block
+func (r *blockUpdateRequest) Validate() error {
+	switch {
+	case r.BlockID == "":
+		return fmt.Errorf("block_id is required")
+	case r.Proposer == "":
+		return fmt.Errorf("proposer is required")
+
+	case r.NumTransactions == nil:
+		return fmt.Errorf("num_transactions is required")
+	case *r.NumTransactions < 0:
+		return fmt.Errorf("num_transactions must not be negative")
+	case r.BlockSizeBytes < 0:
+		return fmt.Errorf("block_size_bytes must not be negative")
+	}
+
+	// Store the proposer in the canonical encoding
+	proposer, err := indexer.NormalizeAddress(r.Proposer)
+	if err != nil {
+		return fmt.Errorf("proposer must be a hex, base64 or bech32 consensus address")
+	}
+	r.Proposer = proposer
+
+	return nil
+}
+
+// putBlockHandler handles the PUT /block/:height endpoint, storing a manually corrected block
+func (a *API) putBlockHandler(c *gin.Context) {
+	height, err := a.parseHeight(c)
+	if err != nil {
+		respondHeightError(c, err)
+		return
+	}
+
+	var req blockUpdateRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	if req.Height != 0 && req.Height != height {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, "height in body does not match the path")
+		return
+	}
+
+	blockDetails := indexer.BlockDetails{
+		Height:          height,
+		BlockID:         strings.ToUpper(req.BlockID),
+		Proposer:        req.Proposer,
+		NumTransactions: *req.NumTransactions,
+		BlockSizeBytes:  req.BlockSizeBytes,
+		AppHash:         req.AppHash,
+		TxMessageTypes:  req.TxMessageTypes,
+		Details:         req.Details,
+	}
+	if req.BlockTime != nil {
+		blockDetails.BlockTime = req.BlockTime.UTC()
+	}
+
+	stored, err := a.indexer.UpsertManualBlock(c.Request.Context(), blockDetails)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, stored)
+}
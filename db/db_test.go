@@ -0,0 +1,163 @@
+//go:build integration
+
+package db_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/db"
+	"github.com/muhammadfarhankt/omniFlix/indexer"
+	"github.com/muhammadfarhankt/omniFlix/internal/testdb"
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Main(m))
+}
+
+// newDB opens a migrated database
+func newDB(t *testing.T) *db.DB {
+	t.Helper()
+	sqlDB := testdb.Open(t)
+	d := &db.DB{DB: sqlDB, Read: sqlDB}
+	if err := d.CreateTable(); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	return d
+}
+
+func TestCreateTable(t *testing.T) {
+	d := newDB(t)
+
+	// Migrations run on every start, so they must be idempotent
+	if err := d.CreateTable(); err != nil {
+		t.Fatalf("second CreateTable: %v", err)
+	}
+	if err := d.CheckSchema(); err != nil {
+		t.Fatalf("CheckSchema: %v", err)
+	}
+}
+
+func TestStoreAndGetBlockDetails(t *testing.T) {
+	d := newDB(t)
+	node := testrpc.NewNode(t)
+	t.Setenv("RPC_URL", node.URL)
+	idx := indexer.NewIndexer(d.DB, d.Read)
+
+	block := testrpc.Block{
+		Height:   5,
+		Hash:     "2E5B3C1A0F9D8E7C6B5A49382716F5E4D3C2B1A09F8E7D6C5B4A392817E6F5D4",
+		AppHash:  "9A8B7C6D5E4F30211203F4E5D6C7B8A99A8B7C6D5E4F30211203F4E5D6C7B8A9",
+		Proposer: "6B2FB9E8D4A9F1C2A5C7E3D1B0F4A6C8E2D9B7A1",
+		Time:     time.Date(2024, 3, 1, 12, 0, 0, 123456000, time.UTC),
+		GasUsed:  []int64{50000, 70000},
+	}
+	node.AddBlock(block)
+
+	fetched, source, err := idx.GetBlockDetails(block.Height)
+	if err != nil {
+		t.Fatalf("GetBlockDetails: %v", err)
+	}
+	if source != indexer.SourceRPC {
+		t.Fatalf("first read source = %q, want %q", source, indexer.SourceRPC)
+	}
+	if abandoned := idx.Drain(10 * time.Second); abandoned != 0 {
+		t.Fatalf("%d stores abandoned", abandoned)
+	}
+
+	stored, source, err := idx.GetBlockDetails(block.Height)
+	if err != nil {
+		t.Fatalf("GetBlockDetails: %v", err)
+	}
+	if source != indexer.SourceDB {
+		t.Fatalf("second read source = %q, want %q", source, indexer.SourceDB)
+	}
+	for _, check := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"height", stored.Height, block.Height},
+		{"block_id", stored.BlockID, block.Hash},
+		{"proposer", stored.Proposer, block.Proposer},
+		{"num_transactions", stored.NumTransactions, 2},
+		{"num_events", stored.NumEvents, 1},
+		{"total_gas_used", stored.TotalGasUsed, int64(120000)},
+		{"total_gas_wanted", stored.TotalGasWanted, int64(240000)},
+		{"app_hash", stored.AppHash, block.AppHash},
+		{"num_transactions fetched", fetched.NumTransactions, stored.NumTransactions},
+		{"app_hash fetched", fetched.AppHash, stored.AppHash},
+	} {
+		if check.got != check.want {
+			t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+		}
+	}
+	// A freshly indexed block always has an app hash; only the genesis block's is empty
+	if stored.AppHash == "" {
+		t.Errorf("app_hash of a freshly indexed block is empty")
+	}
+	if !stored.BlockTime.Equal(block.Time) {
+		t.Errorf("block_time = %v, want %v", stored.BlockTime, block.Time)
+	}
+	if stored.CreatedAt.IsZero() || stored.UpdatedAt.IsZero() || stored.DeletedAt.Valid {
+		t.Errorf("timestamps = %v, %v, %v; want created and updated, not deleted", stored.CreatedAt, stored.UpdatedAt, stored.DeletedAt)
+	}
+
+	// Blocks above the confirmation depth are re-fetched over the stored row
+	block.Hash = "F5D42E5B3C1A0F9D8E7C6B5A49382716F5E4D3C2B1A09F8E7D6C5B4A392817E6"
+	block.GasUsed = []int64{10000}
+	node.AddBlock(block)
+	if _, err := idx.FetchAndStoreBlockDetails(context.Background(), block.Height); err != nil {
+		t.Fatalf("FetchAndStoreBlockDetails: %v", err)
+	}
+	idx.Drain(10 * time.Second)
+
+	updated, _, err := idx.GetBlockDetails(block.Height)
+	if err != nil {
+		t.Fatalf("GetBlockDetails: %v", err)
+	}
+	if updated.BlockID != block.Hash || updated.NumTransactions != 1 || updated.TotalGasUsed != 10000 {
+		t.Errorf("upserted block = %s, %d txs, %d gas; want %s, 1 tx, 10000 gas", updated.BlockID, updated.NumTransactions, updated.TotalGasUsed, block.Hash)
+	}
+	if !updated.CreatedAt.Equal(stored.CreatedAt) {
+		t.Errorf("created_at changed by the upsert: %v, was %v", updated.CreatedAt, stored.CreatedAt)
+	}
+}
+
+func TestCreateTableBackfillsNullTransactionCounts(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	// The blocks table of the first releases, whose rows could hold NULL transaction counts
+	_, err := sqlDB.Exec(`
+		CREATE TABLE blocks (
+			block_height BIGINT PRIMARY KEY,
+			block_id TEXT,
+			proposer_address TEXT,
+			num_transactions INT,
+			details JSONB,
+			created_at TIMESTAMP WITH TIME ZONE,
+			updated_at TIMESTAMP WITH TIME ZONE,
+			deleted_at TIMESTAMP WITH TIME ZONE
+		);
+		INSERT INTO blocks (block_height, num_transactions, created_at, updated_at) VALUES (1, NULL, NOW(), NOW()), (2, 4, NOW(), NOW())`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &db.DB{DB: sqlDB, Read: sqlDB}
+	if err := d.CreateTable(); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+
+	var nulls, total int
+	if err := sqlDB.QueryRow(`SELECT COUNT(*) FILTER (WHERE num_transactions IS NULL), SUM(num_transactions) FROM blocks`).Scan(&nulls, &total); err != nil {
+		t.Fatal(err)
+	}
+	if nulls != 0 || total != 4 {
+		t.Errorf("after the migration %d NULL counts remain and they sum to %d, want 0 and 4", nulls, total)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO blocks (block_height, num_transactions) VALUES (3, NULL)`); err == nil {
+		t.Errorf("NULL num_transactions accepted after the migration")
+	}
+}
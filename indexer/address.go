@@ -0,0 +1,111 @@
+package indexer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// consensusAddressLength is the length in bytes of a consensus (proposer) address
+const consensusAddressLength = 20
+
+// NormalizeAddress converts a consensus address to the canonical encoding stored in proposer_address,
+// upper case hex. It accepts hex in any case, base64 (as returned by some RPC versions and gRPC
+// gateways) and bech32 consensus addresses (e.g. omniflixvalcons1...).
+func NormalizeAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+
+	if len(address) == 2*consensusAddressLength {
+		if b, err := hex.DecodeString(address); err == nil {
+			return strings.ToUpper(hex.EncodeToString(b)), nil
+		}
+	}
+	if b, err := base64.StdEncoding.DecodeString(address); err == nil && len(b) == consensusAddressLength {
+		return strings.ToUpper(hex.EncodeToString(b)), nil
+	}
+	if strings.Contains(address, "1") {
+		b, err := bech32Decode(address)
+		if err == nil && len(b) == consensusAddressLength {
+			return strings.ToUpper(hex.EncodeToString(b)), nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid consensus address %q: expected hex, base64 or bech32", address)
+}
+
+// bech32Charset is the alphabet of the bech32 data part
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Decode decodes the data of a bech32 string (BIP 173), verifying its checksum
+func bech32Decode(s string) ([]byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return nil, errors.New("mixed case bech32 string")
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return nil, errors.New("invalid bech32 separator position")
+	}
+	hrp, data := s[:sep], s[sep+1:]
+
+	values := make([]byte, len(data))
+	for i := 0; i < len(data); i++ {
+		v := strings.IndexByte(bech32Charset, data[i])
+		if v < 0 {
+			return nil, fmt.Errorf("invalid bech32 character %q", data[i])
+		}
+		values[i] = byte(v)
+	}
+
+	// Checksum over the expanded human readable part and the data
+	expanded := make([]byte, 0, 2*len(hrp)+1+len(values))
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	if bech32Polymod(append(expanded, values...)) != 1 {
+		return nil, errors.New("invalid bech32 checksum")
+	}
+
+	// Regroup the 5-bit values, checksum excluded, into bytes
+	var (
+		out  []byte
+		acc  uint32
+		bits uint
+	)
+	for _, v := range values[:len(values)-6] {
+		acc = acc<<5 | uint32(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			out = append(out, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return nil, errors.New("invalid bech32 padding")
+	}
+
+	return out, nil
+}
+
+// bech32Polymod computes the bech32 checksum polynomial
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
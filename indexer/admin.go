@@ -0,0 +1,18 @@
+package indexer
+
+import (
+	"fmt"
+)
+
+// Reset deletes every indexed block, its events and repairs, and the indexer state so the next
+// indexing cycles rebuild the table from scratch. Block tags are operator annotations and survive
+// the reset; known proposers do too, so the rebuild does not alert on every validator again.
+func (idx *Indexer) Reset() error {
+	if _, err := idx.db.Exec("TRUNCATE TABLE blocks, block_events, block_repairs, indexer_state"); err != nil {
+		return fmt.Errorf("error truncating blocks tables: %w", err)
+	}
+	idx.rate.reset()
+	idx.latest.reset()
+	idx.backfillFrontier.Store(0)
+	return nil
+}
@@ -0,0 +1,144 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// backfillDetailsBatchSize is the number of rows without details selected per batch
+const backfillDetailsBatchSize = 100
+
+// DetailsBackfill is the outcome of a BackfillDetails run
+type DetailsBackfill struct {
+	Healed int64 `json:"healed"`
+	Failed int64 `json:"failed"`
+}
+
+// BackfillDetails re-fetches the block results of up to limit blocks at or above from whose details
+// are missing, in batches, and stores them as the details without touching the other columns.
+// Blocks compacted by CompactDetails are left alone. Blocks failing to re-fetch keep their missing
+// details, so the backfill can simply be run again.
+func (idx *Indexer) BackfillDetails(ctx context.Context, from int64, limit int) (DetailsBackfill, error) {
+	compactedBelow, _, err := idx.getState(stateCompactedBelow)
+	if err != nil {
+		return DetailsBackfill{}, err
+	}
+	if from < compactedBelow {
+		from = compactedBelow
+	}
+
+	var (
+		result DetailsBackfill
+		last   = from - 1
+	)
+	for limit > 0 {
+		batch := backfillDetailsBatchSize
+		if batch > limit {
+			batch = limit
+		}
+		heights, err := idx.nullDetailsHeights(ctx, last, batch)
+		if err != nil {
+			return result, err
+		}
+		if len(heights) == 0 {
+			break
+		}
+
+		var g errgroup.Group
+		g.SetLimit(idx.config.Concurrency)
+		for _, height := range heights {
+			height := height
+			g.Go(func() error {
+				if err := idx.backfillBlockDetails(ctx, height); err != nil {
+					log.Printf("Error backfilling details of block %d: %v", height, err)
+					atomic.AddInt64(&result.Failed, 1)
+					return nil
+				}
+				atomic.AddInt64(&result.Healed, 1)
+				return nil
+			})
+		}
+		g.Wait()
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		last = heights[len(heights)-1]
+		limit -= len(heights)
+	}
+
+	return result, nil
+}
+
+// missingDetails matches the rows without details: NULL, or the JSON null stored for a nil
+// json.RawMessage by the releases before the details extraction fix
+const missingDetails = `(details IS NULL OR details = 'null'::jsonb)`
+
+// nullDetailsHeights returns up to limit heights above after whose details are missing, in ascending order
+func (idx *Indexer) nullDetailsHeights(ctx context.Context, after int64, limit int) ([]int64, error) {
+	rows, err := idx.db.QueryContext(ctx, `
+		SELECT block_height FROM blocks
+		WHERE `+missingDetails+` AND block_height > $1
+		ORDER BY block_height
+		LIMIT $2`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching blocks without details: %w", err)
+	}
+	defer rows.Close()
+
+	var heights []int64
+	for rows.Next() {
+		var height int64
+		if err := rows.Scan(&height); err != nil {
+			return nil, fmt.Errorf("error scanning block height: %w", err)
+		}
+		heights = append(heights, height)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blocks without details: %w", err)
+	}
+	return heights, nil
+}
+
+// backfillBlockDetails re-fetches the block results of height and stores them as its details,
+// unless the details were filled in the meantime
+func (idx *Indexer) backfillBlockDetails(ctx context.Context, height int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	resultResult, err := idx.fetchBlockResults(ctx, height)
+	if err != nil {
+		return err
+	}
+	details, err := idx.encodeDetails(resultResult)
+	if err != nil {
+		return err
+	}
+
+	_, err = idx.db.ExecContext(ctx, `
+		UPDATE blocks SET details = $1, updated_at = NOW()
+		WHERE block_height = $2 AND `+missingDetails, details, height)
+	if err != nil {
+		return fmt.Errorf("error storing block details: %w", err)
+	}
+	return nil
+}
+
+// encodeDetails encodes a block results payload, or the DETAILS_FIELDS extracted from it, as block details
+func (idx *Indexer) encodeDetails(resultResult map[string]interface{}) (json.RawMessage, error) {
+	details, err := json.Marshal(resultResult)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding block results: %w", err)
+	}
+	if len(idx.config.DetailsFields) > 0 {
+		if details, err = compactDetails(details, idx.config.DetailsFields); err != nil {
+			return nil, err
+		}
+	}
+	return details, nil
+}
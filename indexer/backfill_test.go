@@ -0,0 +1,48 @@
+//go:build integration
+
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+func TestBackfillDetailsSkipsCompactedBlocks(t *testing.T) {
+	node := testrpc.NewNode(t)
+	for _, height := range []int64{2, 5} {
+		block := testRPCBlock
+		block.Height = height
+		node.AddBlock(block)
+	}
+	t.Setenv("RPC_URL", node.URL)
+	idx := newTestIndexer(t)
+
+	// 2 is compacted below; 5 holds the JSON null of the releases before the details extraction fix
+	insertBlock(t, idx, BlockDetails{Height: 2, Details: json.RawMessage(`{"height":"2"}`)})
+	insertBlock(t, idx, BlockDetails{Height: 5, Details: json.RawMessage("null")})
+	if _, err := idx.CompactDetails(context.Background(), 3); err != nil {
+		t.Fatalf("CompactDetails: %v", err)
+	}
+
+	result, err := idx.BackfillDetails(context.Background(), 0, 10)
+	if err != nil {
+		t.Fatalf("BackfillDetails: %v", err)
+	}
+	if result != (DetailsBackfill{Healed: 1}) {
+		t.Errorf("BackfillDetails = %+v, want 1 healed", result)
+	}
+
+	var compacted, healed bool
+	err = idx.db.QueryRow(`
+		SELECT (SELECT details IS NULL FROM blocks WHERE block_height = 2),
+			(SELECT details ? 'txs_results' FROM blocks WHERE block_height = 5)`).Scan(&compacted, &healed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !compacted || !healed {
+		t.Errorf("block 2 compacted = %v, block 5 healed = %v; want both", compacted, healed)
+	}
+}
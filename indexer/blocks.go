@@ -0,0 +1,391 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrBlockNotFound is returned when a requested block is not indexed or does not exist on chain yet
+	ErrBlockNotFound = errors.New("block not found")
+	// ErrBlockPruned is returned when the node no longer serves a height below its earliest available block
+	ErrBlockPruned = errors.New("block pruned by the node")
+	// ErrRateLimited is returned when the node rejects a request with 429 Too Many Requests
+	ErrRateLimited = errors.New("rate limited by the node")
+)
+
+// queryCanceled is the Postgres error code of a statement cancelled by statement_timeout
+const queryCanceled = "57014"
+
+// IsQueryTimeout reports whether err is a database statement cancelled by DB_STATEMENT_TIMEOUT
+// or by the deadline of its context
+func IsQueryTimeout(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == queryCanceled {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// rpcError converts the 'error' object of a JSON-RPC response into an error wrapping the matching sentinel
+func rpcError(obj interface{}) error {
+	fields, _ := obj.(map[string]interface{})
+	message, _ := fields["message"].(string)
+	data, _ := fields["data"].(string)
+	if data != "" {
+		message = fmt.Sprintf("%s: %s", message, data)
+	}
+
+	switch {
+	case strings.Contains(data, "is not available, lowest height is"):
+		return fmt.Errorf("%w: %s", ErrBlockPruned, message)
+	case strings.Contains(data, "must be less than or equal to the current blockchain height"):
+		return fmt.Errorf("%w: %s", ErrBlockNotFound, message)
+	default:
+		return fmt.Errorf("RPC error: %s", message)
+	}
+}
+
+// blockColumns is the column list of the blocks table read by scanBlock
+const blockColumns = "block_height, COALESCE(block_id, ''), COALESCE(proposer_address, ''), block_time, COALESCE(num_transactions, 0), COALESCE(num_events, 0), COALESCE(total_gas_used, 0), COALESCE(total_gas_wanted, 0), COALESCE(block_size_bytes, 0), COALESCE(app_hash, ''), COALESCE(tx_message_types, '{}'), COALESCE(manually_edited, FALSE), created_at, updated_at, deleted_at, details"
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanBlock scans a row selected with blockColumns into BlockDetails
+func scanBlock(row rowScanner) (BlockDetails, error) {
+	var (
+		blockDetails BlockDetails
+		blockTime    sql.NullTime
+		messageTypes []byte
+	)
+	err := row.Scan(
+		&blockDetails.Height,
+		&blockDetails.BlockID,
+		&blockDetails.Proposer,
+		&blockTime,
+		&blockDetails.NumTransactions,
+		&blockDetails.NumEvents,
+		&blockDetails.TotalGasUsed,
+		&blockDetails.TotalGasWanted,
+		&blockDetails.BlockSizeBytes,
+		&blockDetails.AppHash,
+		&messageTypes,
+		&blockDetails.ManuallyEdited,
+		&blockDetails.CreatedAt,
+		&blockDetails.UpdatedAt,
+		&blockDetails.DeletedAt,
+		&blockDetails.Details,
+	)
+	if err != nil {
+		return blockDetails, err
+	}
+	if blockTime.Valid {
+		blockDetails.BlockTime = blockTime.Time.UTC()
+	}
+	if err := json.Unmarshal(messageTypes, &blockDetails.TxMessageTypes); err != nil {
+		return blockDetails, fmt.Errorf("error decoding tx_message_types: %w", err)
+	}
+	return blockDetails, nil
+}
+
+// nullTime converts a zero time to a SQL NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// GetEarliestIndexedBlock returns the indexed block with the lowest height
+func (idx *Indexer) GetEarliestIndexedBlock() (*BlockDetails, error) {
+	return idx.getEdgeBlock("ASC")
+}
+
+// GetLatestIndexedBlock returns the indexed block with the highest height
+func (idx *Indexer) GetLatestIndexedBlock() (*BlockDetails, error) {
+	return idx.getEdgeBlock("DESC")
+}
+
+// ResolveTipOffset returns the height offset blocks below the highest indexed block, failing with
+// ErrBlockNotFound when nothing is indexed or the height falls below the lowest indexed block
+func (idx *Indexer) ResolveTipOffset(offset int64) (int64, error) {
+	var lowest, highest sql.NullInt64
+	err := idx.readDB.QueryRow("SELECT MIN(block_height), MAX(block_height) FROM blocks").Scan(&lowest, &highest)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching indexed height range: %w", err)
+	}
+	if !highest.Valid {
+		return 0, fmt.Errorf("%w: no blocks are indexed", ErrBlockNotFound)
+	}
+
+	height := highest.Int64 - offset
+	if height < lowest.Int64 {
+		return 0, fmt.Errorf("%w: offset %d from the indexed tip %d is below the lowest indexed block %d",
+			ErrBlockNotFound, offset, highest.Int64, lowest.Int64)
+	}
+	return height, nil
+}
+
+// getEdgeBlock returns the first indexed block in the given height order, walking the height index
+func (idx *Indexer) getEdgeBlock(order string) (*BlockDetails, error) {
+	blockDetails, err := scanBlock(idx.readDB.QueryRow("SELECT " + blockColumns + " FROM blocks ORDER BY block_height " + order + " LIMIT 1"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBlockNotFound
+		}
+		return nil, fmt.Errorf("error fetching block from database: %w", err)
+	}
+
+	return &blockDetails, nil
+}
+
+// StreamBlocks calls fn for every indexed block between from and to (inclusive) in ascending
+// height order, reading rows from a cursor so the range is never held in memory. Up to
+// STREAM_PREFETCH_ROWS rows are read ahead while fn handles earlier ones.
+func (idx *Indexer) StreamBlocks(ctx context.Context, from, to int64, fn func(BlockDetails) error) error {
+	// Stops the read-ahead when fn fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows, err := idx.readDB.QueryContext(ctx, "SELECT "+blockColumns+" FROM blocks WHERE block_height BETWEEN $1 AND $2 ORDER BY block_height", from, to)
+	if err != nil {
+		return fmt.Errorf("error fetching blocks: %w", err)
+	}
+	defer rows.Close()
+
+	if idx.config.StreamPrefetch <= 0 {
+		for rows.Next() {
+			blockDetails, err := scanBlock(rows)
+			if err != nil {
+				return fmt.Errorf("error scanning block: %w", err)
+			}
+			if err := fn(blockDetails); err != nil {
+				return err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating blocks: %w", err)
+		}
+		return nil
+	}
+
+	// The reader owns rows until it closes blocks, so rows is only closed once it is done
+	blocks := make(chan BlockDetails, idx.config.StreamPrefetch)
+	var readErr error
+	go func() {
+		defer close(blocks)
+		for rows.Next() {
+			blockDetails, err := scanBlock(rows)
+			if err != nil {
+				readErr = fmt.Errorf("error scanning block: %w", err)
+				return
+			}
+			select {
+			case blocks <- blockDetails:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			readErr = fmt.Errorf("error iterating blocks: %w", err)
+		}
+	}()
+
+	var fnErr error
+	for blockDetails := range blocks {
+		if fnErr != nil {
+			// Drain until the reader notices the cancellation
+			continue
+		}
+		if fnErr = fn(blockDetails); fnErr != nil {
+			cancel()
+		}
+	}
+	if fnErr != nil {
+		return fnErr
+	}
+	if readErr != nil {
+		return readErr
+	}
+	// A disconnected client stops the reader without a read error
+	return ctx.Err()
+}
+
+// BlockFilter holds the optional filters of block list and count queries; zero values are ignored
+type BlockFilter struct {
+	Proposer  string
+	From      int64
+	To        int64
+	MinEvents int
+	Tag       string
+}
+
+// where builds a parameterized WHERE clause for the filter
+func (f BlockFilter) where() (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.Proposer != "" {
+		add("proposer_address = $%d", f.Proposer)
+	}
+	if f.From > 0 {
+		add("block_height >= $%d", f.From)
+	}
+	if f.To > 0 {
+		add("block_height <= $%d", f.To)
+	}
+	if f.MinEvents > 0 {
+		add("num_events >= $%d", f.MinEvents)
+	}
+	if f.Tag != "" {
+		add("EXISTS (SELECT 1 FROM block_tags WHERE block_tags.block_height = blocks.block_height AND block_tags.tag = $%d)", f.Tag)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// CountBlocks returns the number of indexed blocks matching the filter
+func (idx *Indexer) CountBlocks(filter BlockFilter) (int64, error) {
+	where, args := filter.where()
+
+	var count int64
+	if err := idx.readDB.QueryRow("SELECT COUNT(*) FROM blocks"+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting blocks: %w", err)
+	}
+	return count, nil
+}
+
+// Sort orders of ListBlocks
+const (
+	SortHeightAsc  = "height_asc"
+	SortHeightDesc = "height_desc"
+	SortTxsDesc    = "txs_desc"
+	SortTimeDesc   = "time_desc"
+)
+
+// blockSorts maps the sort orders of ListBlocks to their whitelisted ORDER BY clauses,
+// with the height as a tie-breaker so pages are stable
+var blockSorts = map[string]string{
+	SortHeightAsc:  "block_height ASC",
+	SortHeightDesc: "block_height DESC",
+	SortTxsDesc:    "num_transactions DESC NULLS LAST, block_height DESC",
+	SortTimeDesc:   "block_time DESC NULLS LAST, block_height DESC",
+}
+
+// ValidBlockSort reports whether sort is a sort order accepted by ListBlocks
+func ValidBlockSort(sort string) bool {
+	_, ok := blockSorts[sort]
+	return ok
+}
+
+// TotalBlocks returns the number of indexed blocks. Unless exact is set, it returns the planner's
+// estimate (pg_class.reltuples, kept up to date by autovacuum) instead of scanning the table, falling
+// back to an exact count while the table has never been analyzed. It reports whether the count is exact.
+func (idx *Indexer) TotalBlocks(exact bool) (int64, bool, error) {
+	if !exact {
+		var estimate float64
+		err := idx.readDB.QueryRow("SELECT reltuples FROM pg_class WHERE oid = 'blocks'::regclass").Scan(&estimate)
+		if err != nil {
+			return 0, false, fmt.Errorf("error estimating block count: %w", err)
+		}
+		// reltuples is -1 (PostgreSQL 14+) or 0 before the first ANALYZE
+		if estimate > 0 {
+			return int64(estimate), false, nil
+		}
+	}
+
+	count, err := idx.CountBlocks(BlockFilter{})
+	if err != nil {
+		return 0, false, err
+	}
+	return count, true, nil
+}
+
+// ListBlocks returns a page of the indexed blocks matching the filter, in the given sort order,
+// skipping the large columns fields does not project (all columns without fields)
+func (idx *Indexer) ListBlocks(filter BlockFilter, sort string, limit, offset int, fields []string) ([]BlockDetails, error) {
+	orderBy, ok := blockSorts[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort order %q", sort)
+	}
+	where, args := filter.where()
+	args = append(args, limit, offset)
+
+	rows, err := idx.readDB.Query(fmt.Sprintf("SELECT %s FROM blocks%s ORDER BY %s LIMIT $%d OFFSET $%d",
+		blockColumnsFor(fields), where, orderBy, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []BlockDetails{}
+	for rows.Next() {
+		blockDetails, err := scanBlock(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning block: %w", err)
+		}
+		blocks = append(blocks, blockDetails)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blocks: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// UpsertManualBlock stores a manually corrected block, flagging it as manually_edited so that
+// automatic re-indexing no longer overwrites it
+func (idx *Indexer) UpsertManualBlock(ctx context.Context, blockDetails BlockDetails) (*BlockDetails, error) {
+	messageTypesJSON, err := json.Marshal(blockDetails.TxMessageTypes)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling tx message types to JSON: %w", err)
+	}
+	var details interface{}
+	if len(blockDetails.Details) > 0 {
+		details = string(blockDetails.Details)
+	}
+
+	currentTime := time.Now()
+	_, err = idx.execWithRetry(ctx, `
+		INSERT INTO blocks (block_height, block_id, proposer_address, block_time, num_transactions, block_size_bytes, app_hash, tx_message_types, details, manually_edited, created_at, updated_at, deleted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, TRUE, $10, $11, NULL)
+		ON CONFLICT (block_height) DO UPDATE
+		SET block_id = EXCLUDED.block_id,
+			proposer_address = EXCLUDED.proposer_address,
+			block_time = EXCLUDED.block_time,
+			num_transactions = EXCLUDED.num_transactions,
+			block_size_bytes = EXCLUDED.block_size_bytes,
+			app_hash = EXCLUDED.app_hash,
+			tx_message_types = EXCLUDED.tx_message_types,
+			details = EXCLUDED.details,
+			manually_edited = TRUE,
+			updated_at = EXCLUDED.updated_at`,
+		blockDetails.Height, blockDetails.BlockID, blockDetails.Proposer, nullTime(blockDetails.BlockTime), blockDetails.NumTransactions,
+		blockDetails.BlockSizeBytes, blockDetails.AppHash, messageTypesJSON, details, currentTime, currentTime)
+	if err != nil {
+		return nil, fmt.Errorf("error storing manual block: %w", err)
+	}
+	idx.latest.invalidate(blockDetails.Height)
+
+	stored, err := scanBlock(idx.db.QueryRowContext(ctx, "SELECT "+blockColumns+" FROM blocks WHERE block_height = $1", blockDetails.Height))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching stored block: %w", err)
+	}
+	return &stored, nil
+}
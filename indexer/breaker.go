@@ -0,0 +1,42 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrCycleAborted is returned by StartIndexing when sustained RPC failures open the circuit breaker
+var ErrCycleAborted = errors.New("indexing cycle aborted after consecutive RPC failures")
+
+// circuitBreaker opens once the number of consecutive transient failures reaches its threshold,
+// so an indexing cycle stops early instead of failing every remaining height during an outage
+type circuitBreaker struct {
+	// threshold is the number of consecutive failures opening the breaker; 0 disables it
+	threshold int64
+	failures  atomic.Int64
+	open      atomic.Bool
+}
+
+// isTransient reports whether err may succeed on retry, unlike pruned or not yet produced heights
+// and fetches cancelled by shutdown
+func isTransient(err error) bool {
+	return !errors.Is(err, ErrBlockPruned) && !errors.Is(err, ErrBlockNotFound) && !errors.Is(err, context.Canceled)
+}
+
+// record counts the outcome of a fetch, returning true when it opens the breaker
+func (b *circuitBreaker) record(err error) bool {
+	if err == nil || !isTransient(err) {
+		b.failures.Store(0)
+		return false
+	}
+	if b.threshold <= 0 || b.failures.Add(1) < b.threshold {
+		return false
+	}
+	return b.open.CompareAndSwap(false, true)
+}
+
+// isOpen reports whether the breaker is open
+func (b *circuitBreaker) isOpen() bool {
+	return b.open.Load()
+}
@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// updateChangedColumns compares a freshly fetched block to its stored row and updates only the columns that
+// differ, leaving an identical row untouched. It returns the updated columns and whether the row exists;
+// a manually edited row exists but is never updated.
+func (idx *Indexer) updateChangedColumns(ctx context.Context, blockDetails BlockDetails, messageTypesJSON, detailsJSON []byte) ([]string, bool, error) {
+	// JSONB equality is semantic, so the JSON documents are compared by the database
+	var (
+		stored                 BlockDetails
+		blockTime              sql.NullTime
+		sameTypes, sameDetails bool
+	)
+	err := idx.db.QueryRowContext(ctx, `
+		SELECT COALESCE(block_id, ''), COALESCE(proposer_address, ''), block_time, COALESCE(num_transactions, 0), COALESCE(num_events, 0),
+			COALESCE(total_gas_used, 0), COALESCE(total_gas_wanted, 0), COALESCE(block_size_bytes, 0), COALESCE(app_hash, ''), COALESCE(manually_edited, FALSE),
+			tx_message_types IS NOT DISTINCT FROM $2::jsonb, details IS NOT DISTINCT FROM $3::jsonb
+		FROM blocks WHERE block_height = $1`, blockDetails.Height, messageTypesJSON, detailsJSON).Scan(
+		&stored.BlockID, &stored.Proposer, &blockTime, &stored.NumTransactions, &stored.NumEvents,
+		&stored.TotalGasUsed, &stored.TotalGasWanted, &stored.BlockSizeBytes, &stored.AppHash, &stored.ManuallyEdited,
+		&sameTypes, &sameDetails)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error fetching stored block: %w", err)
+	}
+	if stored.ManuallyEdited {
+		return nil, true, nil
+	}
+
+	var (
+		columns []string
+		args    = []interface{}{blockDetails.Height}
+	)
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		columns = append(columns, column)
+	}
+	if stored.BlockID != blockDetails.BlockID {
+		set("block_id", blockDetails.BlockID)
+	}
+	if stored.Proposer != blockDetails.Proposer {
+		set("proposer_address", blockDetails.Proposer)
+	}
+	// The column keeps microseconds; a NULL block time scans as the zero time
+	if !blockTime.Time.Equal(blockDetails.BlockTime.Truncate(time.Microsecond)) {
+		set("block_time", nullTime(blockDetails.BlockTime))
+	}
+	if stored.NumTransactions != blockDetails.NumTransactions {
+		set("num_transactions", blockDetails.NumTransactions)
+	}
+	if stored.NumEvents != blockDetails.NumEvents {
+		set("num_events", blockDetails.NumEvents)
+	}
+	if stored.TotalGasUsed != blockDetails.TotalGasUsed {
+		set("total_gas_used", blockDetails.TotalGasUsed)
+	}
+	if stored.TotalGasWanted != blockDetails.TotalGasWanted {
+		set("total_gas_wanted", blockDetails.TotalGasWanted)
+	}
+	if stored.BlockSizeBytes != blockDetails.BlockSizeBytes {
+		set("block_size_bytes", blockDetails.BlockSizeBytes)
+	}
+	if stored.AppHash != blockDetails.AppHash {
+		set("app_hash", blockDetails.AppHash)
+	}
+	if !sameTypes {
+		set("tx_message_types", messageTypesJSON)
+	}
+	if !sameDetails {
+		set("details", detailsJSON)
+	}
+	if len(columns) == 0 {
+		return nil, true, nil
+	}
+
+	assignments := make([]string, len(columns), len(columns)+1)
+	for i, column := range columns {
+		assignments[i] = fmt.Sprintf("%s = $%d", column, i+2)
+	}
+	args = append(args, time.Now())
+	assignments = append(assignments, fmt.Sprintf("updated_at = $%d", len(args)))
+
+	_, err = idx.execWithRetry(ctx, "UPDATE blocks SET "+strings.Join(assignments, ", ")+" WHERE block_height = $1 AND manually_edited IS NOT TRUE", args...)
+	if err != nil {
+		return nil, true, fmt.Errorf("error updating block: %w", err)
+	}
+	return columns, true, nil
+}
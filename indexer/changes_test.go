@@ -0,0 +1,36 @@
+//go:build integration
+
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+func TestRefreshFillsNullBlockID(t *testing.T) {
+	node := testrpc.NewNode(t)
+	node.AddBlock(testRPCBlock)
+	t.Setenv("RPC_URL", node.URL)
+	idx := newTestIndexer(t)
+
+	// An incomplete row, stored without block_id and proposer
+	insertBlock(t, idx, BlockDetails{Height: testRPCBlock.Height, NumTransactions: 2})
+
+	if _, err := idx.RefreshBlockDetails(testRPCBlock.Height); err != nil {
+		t.Fatalf("RefreshBlockDetails: %v", err)
+	}
+	if abandoned := idx.Drain(10 * time.Second); abandoned != 0 {
+		t.Fatalf("%d stores abandoned", abandoned)
+	}
+
+	block, _, err := idx.GetBlockDetails(testRPCBlock.Height)
+	if err != nil {
+		t.Fatalf("GetBlockDetails: %v", err)
+	}
+	if block.BlockID != testRPCBlock.Hash || block.Proposer != testRPCBlock.Proposer || block.AppHash != testRPCBlock.AppHash {
+		t.Errorf("refreshed block = %s, %s, %s; want %s, %s, %s",
+			block.BlockID, block.Proposer, block.AppHash, testRPCBlock.Hash, testRPCBlock.Proposer, testRPCBlock.AppHash)
+	}
+}
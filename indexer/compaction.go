@@ -0,0 +1,92 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/config"
+)
+
+// compactBatchSize is the number of rows stripped per UPDATE, keeping each transaction short
+const compactBatchSize = 10000
+
+// stateCompactedBelow is the indexer_state key of the highest cutoff details were compacted below
+const stateCompactedBelow = "compacted_below"
+
+// CompactDetails strips the details of blocks below height, keeping their summary columns.
+// It returns the number of compacted blocks.
+func (idx *Indexer) CompactDetails(ctx context.Context, before int64) (int64, error) {
+	// The cutoff is recorded first, so BackfillDetails never re-fetches the details being stripped
+	compactedBelow, _, err := idx.getState(stateCompactedBelow)
+	if err != nil {
+		return 0, err
+	}
+	if before > compactedBelow {
+		if err := idx.setState(stateCompactedBelow, before); err != nil {
+			return 0, err
+		}
+	}
+
+	var total int64
+	for {
+		result, err := idx.db.ExecContext(ctx, `
+			UPDATE blocks SET details = NULL, updated_at = NOW()
+			WHERE block_height IN (
+				SELECT block_height FROM blocks
+				WHERE block_height < $1 AND details IS NOT NULL
+				LIMIT $2
+			)`, before, compactBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("error compacting block details: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("error counting compacted blocks: %w", err)
+		}
+		total += n
+		if n < compactBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// RunCompaction periodically strips the details of blocks more than COMPACT_KEEP_BLOCKS below
+// the latest indexed block, every COMPACT_INTERVAL (disabled when 0), until ctx is cancelled
+func (idx *Indexer) RunCompaction(ctx context.Context) {
+	interval := config.Duration("COMPACT_INTERVAL", 0)
+	keep := config.Int64("COMPACT_KEEP_BLOCKS", 100000)
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		latest, err := idx.GetLatestIndexedBlock()
+		if err != nil {
+			log.Printf("Error finding latest block for compaction: %v", err)
+			continue
+		}
+		before := latest.Height - keep
+		if before <= 0 {
+			continue
+		}
+
+		compacted, err := idx.CompactDetails(ctx, before)
+		if err != nil {
+			log.Printf("Error compacting blocks below %d: %v", before, err)
+			continue
+		}
+		if compacted > 0 {
+			log.Printf("Compacted details of %d blocks below height %d", compacted, before)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package indexer
+
+import "log"
+
+// adaptConcurrency returns the concurrency of an indexing cycle with lag blocks left to index,
+// scaling linearly from INDEX_MIN_CONCURRENCY at the tip to INDEX_CONCURRENCY once the lag reaches
+// INDEX_FULL_CONCURRENCY_LAG, so catch-up is fast while steady state stays light on the RPC
+func (idx *Indexer) adaptConcurrency(lag int64) int {
+	minimum, maximum := idx.config.MinConcurrency, idx.config.Concurrency
+	concurrency := maximum
+	if full := idx.config.FullConcurrencyLag; full > 0 && lag < full {
+		concurrency = minimum + int(int64(maximum-minimum)*lag/full)
+	}
+
+	if previous := idx.concurrency.Swap(int64(concurrency)); previous != int64(concurrency) {
+		log.Printf("Indexing concurrency set to %d for a lag of %d blocks (was %d)", concurrency, lag, previous)
+	}
+	return concurrency
+}
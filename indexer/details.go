@@ -0,0 +1,27 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// compactDetails builds the stored details from an allowlist of gjson paths evaluated against the
+// block results payload, as an object keyed by path. Paths matching nothing are left out.
+// For example "txs_results.#.gas_used" keeps the gas used by each tx and "finalize_block_events.#.type"
+// the block event types.
+func compactDetails(payload []byte, fields []string) (json.RawMessage, error) {
+	compact := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if result := gjson.GetBytes(payload, field); result.Exists() {
+			compact[field] = json.RawMessage(result.Raw)
+		}
+	}
+
+	details, err := json.Marshal(compact)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding compact details: %w", err)
+	}
+	return details, nil
+}
@@ -0,0 +1,33 @@
+package indexer
+
+import (
+	"log"
+	"time"
+)
+
+// drainPollInterval is how often Drain checks for unfinished work
+const drainPollInterval = 50 * time.Millisecond
+
+// beginWork counts a block fetch or store as in flight until the returned function is called
+func (idx *Indexer) beginWork() func() {
+	idx.pending.Add(1)
+	return func() { idx.pending.Add(-1) }
+}
+
+// Drain waits up to timeout for in-flight block fetches and stores to finish once indexing has been
+// cancelled, and returns the number of them abandoned
+func (idx *Indexer) Drain(timeout time.Duration) int64 {
+	deadline := time.Now().Add(timeout)
+	for {
+		pending := idx.pending.Load()
+		if pending <= 0 {
+			log.Printf("Indexing drained")
+			return 0
+		}
+		if time.Now().After(deadline) {
+			log.Printf("Shutdown timeout reached, abandoning %d unfinished block fetches/stores", pending)
+			return pending
+		}
+		time.Sleep(drainPollInterval)
+	}
+}
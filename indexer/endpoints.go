@@ -0,0 +1,118 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// endpoint is an RPC node of the pool along with its failure tracking
+type endpoint struct {
+	url string
+
+	mu             sync.Mutex
+	failures       int
+	unhealthyUntil time.Time
+}
+
+// EndpointHealth is the health of an RPC endpoint as reported by /status
+type EndpointHealth struct {
+	URL                 string     `json:"url"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	UnhealthyUntil      *time.Time `json:"unhealthy_until,omitempty"`
+}
+
+// endpointPool spreads requests across RPC nodes round-robin, skipping a node for a cooldown
+// once it fails maxFailures times in a row
+type endpointPool struct {
+	endpoints   []*endpoint
+	next        atomic.Uint64
+	maxFailures int
+	cooldown    time.Duration
+}
+
+// newEndpointPool creates a pool over urls, which must not be empty
+func newEndpointPool(urls []string, maxFailures int, cooldown time.Duration) *endpointPool {
+	pool := &endpointPool{maxFailures: maxFailures, cooldown: cooldown}
+	for _, url := range urls {
+		pool.endpoints = append(pool.endpoints, &endpoint{url: url})
+	}
+	return pool
+}
+
+// pick returns the next healthy endpoint in round-robin order, or the next endpoint when all are cooling down
+func (p *endpointPool) pick() *endpoint {
+	now := time.Now()
+	start := p.next.Add(1) - 1
+	for i := 0; i < len(p.endpoints); i++ {
+		e := p.endpoints[(start+uint64(i))%uint64(len(p.endpoints))]
+		e.mu.Lock()
+		healthy := !now.Before(e.unhealthyUntil)
+		e.mu.Unlock()
+		if healthy {
+			return e
+		}
+	}
+	return p.endpoints[start%uint64(len(p.endpoints))]
+}
+
+// report records the outcome of a request to e. Only transient errors count as failures of the node;
+// pruned or not yet produced heights are answers, not outages.
+func (p *endpointPool) report(e *endpoint, err error) {
+	// A cancelled request says nothing about the node
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err == nil || !isTransient(err) {
+		e.failures = 0
+		e.unhealthyUntil = time.Time{}
+		return
+	}
+	e.failures++
+	if p.maxFailures > 0 && e.failures >= p.maxFailures {
+		e.unhealthyUntil = time.Now().Add(p.cooldown)
+	}
+}
+
+// do calls fn with the base URL of successive endpoints until one succeeds or fails with a
+// non-transient error, trying each endpoint at most once
+func (p *endpointPool) do(fn func(baseURL string) error) error {
+	var err error
+	for attempt := 0; attempt < len(p.endpoints); attempt++ {
+		e := p.pick()
+		err = fn(e.url)
+		p.report(e, err)
+		if err == nil || !isTransient(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// health returns the health of every endpoint, in configuration order
+func (p *endpointPool) health() []EndpointHealth {
+	now := time.Now()
+	health := make([]EndpointHealth, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		h := EndpointHealth{URL: e.url, Healthy: !now.Before(e.unhealthyUntil), ConsecutiveFailures: e.failures}
+		if !h.Healthy {
+			until := e.unhealthyUntil.UTC()
+			h.UnhealthyUntil = &until
+		}
+		e.mu.Unlock()
+		health = append(health, h)
+	}
+	return health
+}
+
+// RPCEndpoints returns the health of the configured RPC endpoints
+func (idx *Indexer) RPCEndpoints() []EndpointHealth {
+	return idx.rpc.health()
+}
@@ -0,0 +1,189 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Event phases stored in block_events.phase
+const (
+	PhaseBeginBlock    = "begin_block"
+	PhaseEndBlock      = "end_block"
+	PhaseFinalizeBlock = "finalize_block"
+	PhaseTx            = "tx"
+)
+
+// BlockEvent represents an ABCI event emitted while executing a block
+type BlockEvent struct {
+	Phase string `json:"phase"`
+	// TxIndex is the index of the emitting tx for PhaseTx events
+	TxIndex    *int            `json:"tx_index,omitempty"`
+	EventIndex int             `json:"event_index"`
+	Type       string          `json:"type"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+// extractEvents normalizes the events of a /block_results result. Tendermint reports block events as
+// begin_block_events/end_block_events while CometBFT 0.38+ reports finalize_block_events; both shapes
+// are supported, along with the per-tx events of txs_results.
+func extractEvents(result map[string]interface{}) ([]BlockEvent, error) {
+	var events []BlockEvent
+
+	for _, phase := range []struct {
+		name  string
+		field string
+	}{
+		{PhaseBeginBlock, "begin_block_events"},
+		{PhaseEndBlock, "end_block_events"},
+		{PhaseFinalizeBlock, "finalize_block_events"},
+	} {
+		phaseEvents, err := parseEvents(result[phase.field], phase.name, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", phase.field, err)
+		}
+		events = append(events, phaseEvents...)
+	}
+
+	txsResults, _ := result["txs_results"].([]interface{})
+	txEventIndex := 0
+	for i, txResult := range txsResults {
+		txResultMap, ok := txResult.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected type for tx result %d: %T", i, txResult)
+		}
+		txIndex := i
+		txEvents, err := parseEvents(txResultMap["events"], PhaseTx, &txIndex, txEventIndex)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing events of tx %d: %w", i, err)
+		}
+		txEventIndex += len(txEvents)
+		events = append(events, txEvents...)
+	}
+
+	return events, nil
+}
+
+// countBlockEvents returns the number of block-level (begin/end/finalize block) events, excluding tx events
+func countBlockEvents(events []BlockEvent) int {
+	count := 0
+	for _, event := range events {
+		if event.Phase != PhaseTx {
+			count++
+		}
+	}
+	return count
+}
+
+// parseEvents converts a JSON event array (null for none) into BlockEvents numbered from firstIndex
+func parseEvents(raw interface{}, phase string, txIndex *int, firstIndex int) ([]BlockEvent, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected type for events: %T", raw)
+	}
+
+	events := make([]BlockEvent, 0, len(list))
+	for i, item := range list {
+		event, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected type for event %d: %T", i, item)
+		}
+		eventType, _ := event["type"].(string)
+
+		attributes := event["attributes"]
+		if attributes == nil {
+			attributes = []interface{}{}
+		}
+		attributesJSON, err := json.Marshal(attributes)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding attributes of event %d: %w", i, err)
+		}
+
+		events = append(events, BlockEvent{
+			Phase:      phase,
+			TxIndex:    txIndex,
+			EventIndex: firstIndex + i,
+			Type:       eventType,
+			Attributes: attributesJSON,
+		})
+	}
+	return events, nil
+}
+
+// storeEvents replaces the stored events of a block in a single transaction
+func (idx *Indexer) storeEvents(ctx context.Context, height int64, events []BlockEvent) error {
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM block_events WHERE block_height = $1", height); err != nil {
+		return fmt.Errorf("error deleting block events: %w", err)
+	}
+
+	if len(events) > 0 {
+		stmt, err := tx.PrepareContext(ctx, pq.CopyIn("block_events", "block_height", "phase", "tx_index", "event_index", "type", "attributes"))
+		if err != nil {
+			return fmt.Errorf("error preparing block events copy: %w", err)
+		}
+		for _, event := range events {
+			if _, err := stmt.ExecContext(ctx, height, event.Phase, event.TxIndex, event.EventIndex, event.Type, string(event.Attributes)); err != nil {
+				stmt.Close()
+				return fmt.Errorf("error copying block event: %w", err)
+			}
+		}
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			stmt.Close()
+			return fmt.Errorf("error flushing block events copy: %w", err)
+		}
+		if err := stmt.Close(); err != nil {
+			return fmt.Errorf("error closing block events copy: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing block events: %w", err)
+	}
+	return nil
+}
+
+// GetBlockEvents returns the stored events of a block, in phase order then emission order,
+// optionally only those of type eventType
+func (idx *Indexer) GetBlockEvents(ctx context.Context, height int64, eventType string) ([]BlockEvent, error) {
+	rows, err := idx.readDB.QueryContext(ctx, `
+		SELECT phase, tx_index, event_index, type, COALESCE(attributes, '[]')
+		FROM block_events
+		WHERE block_height = $1 AND ($2 = '' OR type = $2)
+		ORDER BY CASE phase WHEN 'begin_block' THEN 0 WHEN 'tx' THEN 1 ELSE 2 END, event_index`, height, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching block events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []BlockEvent{}
+	for rows.Next() {
+		var (
+			event   BlockEvent
+			txIndex sql.NullInt64
+		)
+		if err := rows.Scan(&event.Phase, &txIndex, &event.EventIndex, &event.Type, &event.Attributes); err != nil {
+			return nil, fmt.Errorf("error scanning block event: %w", err)
+		}
+		if txIndex.Valid {
+			i := int(txIndex.Int64)
+			event.TxIndex = &i
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating block events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,62 @@
+//go:build integration
+
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+func TestStoredBlockEventPhases(t *testing.T) {
+	for _, tc := range []struct {
+		fixture string
+		height  int64
+		// events are the phase:type of the stored events in GetBlockEvents order
+		events []string
+	}{
+		{"block_results_begin_end.json", 9102331, []string{
+			"begin_block:coin_received", "begin_block:mint", "tx:tx", "tx:transfer", "tx:tx", "end_block:complete_unbonding",
+		}},
+		{"block_results_finalize.json", 14480127, []string{
+			"tx:tx", "tx:message", "tx:transfer", "finalize_block:mint", "finalize_block:commission", "finalize_block:complete_unbonding",
+		}},
+	} {
+		t.Run(tc.fixture, func(t *testing.T) {
+			results, err := os.ReadFile(filepath.Join("testdata", tc.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			block := testRPCBlock
+			block.Height = tc.height
+			node := testrpc.NewNode(t)
+			node.Handle(fmt.Sprintf("/block?height=%d", tc.height), testrpc.BlockResponse(block))
+			node.Handle(fmt.Sprintf("/block_results?height=%d", tc.height), string(results))
+			t.Setenv("RPC_URL", node.URL)
+			idx := newTestIndexer(t)
+
+			if _, err := idx.FetchAndStoreBlockDetails(context.Background(), tc.height); err != nil {
+				t.Fatalf("FetchAndStoreBlockDetails: %v", err)
+			}
+			idx.Drain(10 * time.Second)
+
+			events, err := idx.GetBlockEvents(context.Background(), tc.height, "")
+			if err != nil {
+				t.Fatalf("GetBlockEvents: %v", err)
+			}
+			var got []string
+			for _, event := range events {
+				got = append(got, event.Phase+":"+event.Type)
+			}
+			if !reflect.DeepEqual(got, tc.events) {
+				t.Errorf("stored events = %v, want %v", got, tc.events)
+			}
+		})
+	}
+}
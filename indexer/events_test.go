@@ -0,0 +1,106 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+func TestBlockResultsEventFormats(t *testing.T) {
+	type event struct {
+		phase   string
+		txIndex int // -1 for block events
+		index   int
+		typ     string
+	}
+
+	for _, tc := range []struct {
+		fixture   string
+		height    int64
+		txs       int
+		gasUsed   int64
+		gasWanted int64
+		events    []event
+	}{
+		{
+			// Tendermint 0.34 / CometBFT 0.37 report block events in the begin and end block phases
+			fixture: "block_results_begin_end.json", height: 9102331,
+			txs: 2, gasUsed: 142236, gasWanted: 350000,
+			events: []event{
+				{PhaseBeginBlock, -1, 0, "coin_received"},
+				{PhaseBeginBlock, -1, 1, "mint"},
+				{PhaseEndBlock, -1, 0, "complete_unbonding"},
+				{PhaseTx, 0, 0, "tx"},
+				{PhaseTx, 0, 1, "transfer"},
+				{PhaseTx, 1, 2, "tx"},
+			},
+		},
+		{
+			// CometBFT 0.38+ reports them all as finalize block events
+			fixture: "block_results_finalize.json", height: 14480127,
+			txs: 1, gasUsed: 102876, gasWanted: 250000,
+			events: []event{
+				{PhaseFinalizeBlock, -1, 0, "mint"},
+				{PhaseFinalizeBlock, -1, 1, "commission"},
+				{PhaseFinalizeBlock, -1, 2, "complete_unbonding"},
+				{PhaseTx, 0, 0, "tx"},
+				{PhaseTx, 0, 1, "message"},
+				{PhaseTx, 0, 2, "transfer"},
+			},
+		},
+	} {
+		t.Run(tc.fixture, func(t *testing.T) {
+			results, err := os.ReadFile(filepath.Join("testdata", tc.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			block := testRPCBlock
+			block.Height = tc.height
+			node := testrpc.NewNode(t)
+			node.Handle(fmt.Sprintf("/block?height=%d", tc.height), testrpc.BlockResponse(block))
+			node.Handle(fmt.Sprintf("/block_results?height=%d", tc.height), string(results))
+			idx := newRPCIndexer(t, node.URL)
+
+			details, err := idx.getBlockResults(context.Background(), tc.height)
+			if err != nil {
+				t.Fatalf("getBlockResults: %v", err)
+			}
+			if details.NumTransactions != tc.txs || details.TotalGasUsed != tc.gasUsed || details.TotalGasWanted != tc.gasWanted {
+				t.Errorf("block = %d txs, %d/%d gas; want %d txs, %d/%d gas",
+					details.NumTransactions, details.TotalGasUsed, details.TotalGasWanted, tc.txs, tc.gasUsed, tc.gasWanted)
+			}
+
+			blockEvents := 0
+			for _, want := range tc.events {
+				if want.phase != PhaseTx {
+					blockEvents++
+				}
+			}
+			if details.NumEvents != blockEvents {
+				t.Errorf("num_events = %d, want the %d block events", details.NumEvents, blockEvents)
+			}
+
+			if len(details.Events) != len(tc.events) {
+				t.Fatalf("got %d events, want %d: %+v", len(details.Events), len(tc.events), details.Events)
+			}
+			for i, got := range details.Events {
+				want := tc.events[i]
+				txIndex := -1
+				if got.TxIndex != nil {
+					txIndex = *got.TxIndex
+				}
+				if got.Phase != want.phase || txIndex != want.txIndex || got.EventIndex != want.index || got.Type != want.typ {
+					t.Errorf("event %d = %s tx %d #%d %s, want %s tx %d #%d %s",
+						i, got.Phase, txIndex, got.EventIndex, got.Type, want.phase, want.txIndex, want.index, want.typ)
+				}
+				if len(got.Attributes) < 2 || got.Attributes[0] != '[' {
+					t.Errorf("event %d attributes = %s, want the JSON array of the response", i, got.Attributes)
+				}
+			}
+		})
+	}
+}
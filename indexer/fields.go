@@ -0,0 +1,66 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// BlockFields lists the JSON fields of BlockDetails a response can be projected to
+var BlockFields = []string{
+	"height", "block_id", "num_transactions", "num_events", "total_gas_used", "total_gas_wanted", "proposer",
+	"block_time", "block_size_bytes", "app_hash", "tx_message_types", "manually_edited", "created_at", "updated_at",
+	"deleted_at", "details",
+}
+
+// ValidBlockField reports whether field is one of BlockFields
+func ValidBlockField(field string) bool {
+	return hasField(BlockFields, field)
+}
+
+// hasField reports whether fields contains field
+func hasField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// blockColumnsFor returns blockColumns with the large JSONB columns replaced by empty placeholders
+// unless fields projects them, so scanBlock still applies. No fields selects every column.
+func blockColumnsFor(fields []string) string {
+	columns := blockColumns
+	if len(fields) == 0 {
+		return columns
+	}
+	if !hasField(fields, "tx_message_types") {
+		columns = strings.Replace(columns, "COALESCE(tx_message_types, '{}')", "'{}'::jsonb", 1)
+	}
+	if !hasField(fields, "details") {
+		columns = strings.TrimSuffix(columns, ", details") + ", NULL::jsonb"
+	}
+	return columns
+}
+
+// Project returns the JSON encoding of the block reduced to fields. Fields the block omits
+// when empty, like details, are left out.
+func (b BlockDetails) Project(fields []string) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding block: %w", err)
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, fmt.Errorf("error decoding block: %w", err)
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			projected[field] = value
+		}
+	}
+	return projected, nil
+}
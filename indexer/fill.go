@@ -0,0 +1,76 @@
+package indexer
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// RangeBlocks is a contiguous height range of blocks, with the heights fetched on demand to fill it
+type RangeBlocks struct {
+	Blocks []BlockDetails `json:"blocks"`
+	// Fetched are the heights fetched from the chain for this request, the others were served from the database
+	Fetched []int64 `json:"fetched"`
+	// Missing are the heights left out: not indexed and not fetched, because fetching was off, over the cap or failed
+	Missing []int64 `json:"missing"`
+}
+
+// GetRange returns the indexed blocks between from and to (inclusive) in ascending order. When fetch is
+// set, up to maxFetches missing heights are fetched and stored first, through the bounded fetch path.
+func (idx *Indexer) GetRange(from, to int64, fetch bool, maxFetches int) (*RangeBlocks, error) {
+	blocks, err := idx.ListBlocks(BlockFilter{From: from, To: to}, SortHeightAsc, int(to-from+1), 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	indexed := make(map[int64]bool, len(blocks))
+	for _, block := range blocks {
+		indexed[block.Height] = true
+	}
+	result := &RangeBlocks{Blocks: blocks, Fetched: []int64{}, Missing: []int64{}}
+	var toFetch []int64
+	for height := from; height <= to; height++ {
+		if indexed[height] {
+			continue
+		}
+		if fetch && len(toFetch) < maxFetches {
+			toFetch = append(toFetch, height)
+		} else {
+			result.Missing = append(result.Missing, height)
+		}
+	}
+	if len(toFetch) == 0 {
+		return result, nil
+	}
+
+	var (
+		g  errgroup.Group
+		mu sync.Mutex
+	)
+	g.SetLimit(idx.config.Concurrency)
+	for _, height := range toFetch {
+		height := height
+		g.Go(func() error {
+			blockDetails, err := idx.FetchAndStoreBlockDetails(context.Background(), height)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("Error fetching block %d on demand: %v", height, err)
+				result.Missing = append(result.Missing, height)
+				return nil
+			}
+			result.Blocks = append(result.Blocks, blockDetails)
+			result.Fetched = append(result.Fetched, height)
+			return nil
+		})
+	}
+	g.Wait()
+
+	sort.Slice(result.Blocks, func(i, j int) bool { return result.Blocks[i].Height < result.Blocks[j].Height })
+	sort.Slice(result.Fetched, func(i, j int) bool { return result.Fetched[i] < result.Fetched[j] })
+	sort.Slice(result.Missing, func(i, j int) bool { return result.Missing[i] < result.Missing[j] })
+	return result, nil
+}
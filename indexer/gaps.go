@@ -0,0 +1,48 @@
+package indexer
+
+import (
+	"fmt"
+)
+
+// HeightRange represents an inclusive range of block heights
+type HeightRange struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// GetMissingRanges returns the heights between from and to (inclusive) that are not indexed,
+// collapsed into ranges of consecutive missing heights
+func (idx *Indexer) GetMissingRanges(from, to int64) ([]HeightRange, error) {
+	// The range bounds are added as sentinels so leading and trailing holes are reported too
+	rows, err := idx.db.Query(`
+		WITH heights AS (
+			SELECT block_height FROM blocks WHERE block_height BETWEEN $1 AND $2
+			UNION ALL SELECT $1::BIGINT - 1
+			UNION ALL SELECT $2::BIGINT + 1
+		)
+		SELECT prev + 1, block_height - 1
+		FROM (
+			SELECT block_height, LAG(block_height) OVER (ORDER BY block_height) AS prev
+			FROM heights
+		) h
+		WHERE block_height - prev > 1
+		ORDER BY block_height`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching missing ranges: %w", err)
+	}
+	defer rows.Close()
+
+	ranges := []HeightRange{}
+	for rows.Next() {
+		var r HeightRange
+		if err := rows.Scan(&r.From, &r.To); err != nil {
+			return nil, fmt.Errorf("error scanning missing range: %w", err)
+		}
+		ranges = append(ranges, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating missing ranges: %w", err)
+	}
+
+	return ranges, nil
+}
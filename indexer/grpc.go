@@ -0,0 +1,208 @@
+package indexer
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Fetch modes selectable through FETCH_MODE
+const (
+	FetchModeRPC  = "rpc"
+	FetchModeGRPC = "grpc"
+)
+
+// getBlockByHeightMethod is the cosmos base tendermint service method returning a block by height
+const getBlockByHeightMethod = "/cosmos.base.tendermint.v1beta1.Service/GetBlockByHeight"
+
+// rawCodec passes pre-encoded protobuf messages through gRPC unchanged,
+// so the block can be decoded field by field without generated cosmos types
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "proto" }
+
+// grpcClient lazily dials the configured gRPC endpoint
+type grpcClient struct {
+	addr     string
+	insecure bool
+
+	once sync.Once
+	conn *grpc.ClientConn
+	err  error
+}
+
+// connection returns the shared client connection, dialing it on first use
+func (g *grpcClient) connection() (*grpc.ClientConn, error) {
+	g.once.Do(func() {
+		creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		if g.insecure {
+			creds = insecure.NewCredentials()
+		}
+		g.conn, g.err = grpc.Dial(g.addr, grpc.WithTransportCredentials(creds))
+		if g.err != nil {
+			g.err = fmt.Errorf("error dialing gRPC endpoint %s: %w", g.addr, g.err)
+		}
+	})
+	return g.conn, g.err
+}
+
+// getBlockGRPC fetches block_id, proposer and txs of a block from the cosmos gRPC
+// GetBlockByHeight query, extracting the same fields as getBlock
+func (idx *Indexer) getBlockGRPC(ctx context.Context, height int64) (BlockDetails, error) {
+	if err := idx.acquireRPC(ctx); err != nil {
+		return BlockDetails{}, err
+	}
+	defer idx.releaseRPC()
+
+	conn, err := idx.grpc.connection()
+	if err != nil {
+		return BlockDetails{}, err
+	}
+
+	// GetBlockByHeightRequest.height = 1
+	request := protowire.AppendTag(nil, 1, protowire.VarintType)
+	request = protowire.AppendVarint(request, uint64(height))
+
+	ctx, cancel := context.WithTimeout(ctx, idx.client.Timeout)
+	defer cancel()
+
+	var response []byte
+	if err := conn.Invoke(ctx, getBlockByHeightMethod, &request, &response, grpc.ForceCodec(rawCodec{})); err != nil {
+		return BlockDetails{}, fmt.Errorf("error fetching block from gRPC: %w", err)
+	}
+
+	// GetBlockByHeightResponse: block_id = 1, block = 2
+	blockID, err := bytesField(response, 1, 1) // BlockID.hash = 1
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error extracting block_id from gRPC response: %w", err)
+	}
+	block, err := bytesField(response, 2)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error extracting block from gRPC response: %w", err)
+	}
+	proposer, err := bytesField(block, 1, 14) // Block.header = 1, Header.proposer_address = 14
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error extracting proposer_address from gRPC response: %w", err)
+	}
+
+	// Block.header = 1, Header.app_hash = 11; an empty app hash, as in the genesis block, is omitted
+	header, err := bytesField(block, 1)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error extracting header from gRPC response: %w", err)
+	}
+	appHash, err := bytesFields(header, 11)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error extracting app_hash from gRPC response: %w", err)
+	}
+	var appHashHex string
+	if len(appHash) > 0 {
+		appHashHex = strings.ToUpper(hex.EncodeToString(appHash[len(appHash)-1]))
+	}
+
+	// Block.header = 1, Header.time = 4
+	timestamp, err := bytesField(block, 1, 4)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error extracting time from gRPC response: %w", err)
+	}
+	blockTime, err := decodeTimestamp(timestamp)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error decoding block time: %w", err)
+	}
+
+	// Block.data = 2, Data.txs = 1; empty blocks omit the data message entirely
+	var txs [][]byte
+	if data, err := bytesFields(block, 2); err != nil {
+		return BlockDetails{}, fmt.Errorf("error extracting txs from gRPC response: %w", err)
+	} else if len(data) > 0 {
+		if txs, err = bytesFields(data[0], 1); err != nil {
+			return BlockDetails{}, fmt.Errorf("error extracting txs from gRPC response: %w", err)
+		}
+	}
+	var blockSize int64
+	for _, tx := range txs {
+		blockSize += int64(len(tx))
+	}
+
+	return BlockDetails{
+		BlockID:        strings.ToUpper(hex.EncodeToString(blockID)),
+		Proposer:       strings.ToUpper(hex.EncodeToString(proposer)),
+		BlockTime:      blockTime,
+		BlockSizeBytes: blockSize,
+		AppHash:        appHashHex,
+		TxMessageTypes: countMessageTypes(txs),
+	}, nil
+}
+
+// bytesField follows a path of nested length-delimited field numbers and returns the last value
+func bytesField(b []byte, path ...protowire.Number) ([]byte, error) {
+	for _, num := range path {
+		values, err := bytesFields(b, num)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("field %d not found", num)
+		}
+		b = values[len(values)-1]
+	}
+	return b, nil
+}
+
+// decodeTimestamp decodes a google.protobuf.Timestamp (seconds = 1, nanos = 2)
+func decodeTimestamp(b []byte) (time.Time, error) {
+	var seconds, nanos int64
+	for len(b) > 0 {
+		num, typ, tagLen := protowire.ConsumeTag(b)
+		if tagLen < 0 {
+			return time.Time{}, protowire.ParseError(tagLen)
+		}
+		b = b[tagLen:]
+
+		if typ == protowire.VarintType && (num == 1 || num == 2) {
+			value, valueLen := protowire.ConsumeVarint(b)
+			if valueLen < 0 {
+				return time.Time{}, protowire.ParseError(valueLen)
+			}
+			if num == 1 {
+				seconds = int64(value)
+			} else {
+				nanos = int64(value)
+			}
+			b = b[valueLen:]
+			continue
+		}
+
+		valueLen := protowire.ConsumeFieldValue(num, typ, b)
+		if valueLen < 0 {
+			return time.Time{}, protowire.ParseError(valueLen)
+		}
+		b = b[valueLen:]
+	}
+	return time.Unix(seconds, nanos).UTC(), nil
+}
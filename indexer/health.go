@@ -0,0 +1,49 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// watchdog records when blocks were last stored to detect stalled indexing
+type watchdog struct {
+	mu sync.Mutex
+	// lastStore is the time of the last successful block store, or the start time before the first one
+	lastStore time.Time
+	// chainHeight is the chain height known at lastStore
+	chainHeight int64
+}
+
+// recordStore records a successful block store at the given known chain height
+func (w *watchdog) recordStore(chainHeight int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lastStore = time.Now()
+	w.chainHeight = chainHeight
+}
+
+// Ping checks the database connection
+func (idx *Indexer) Ping(ctx context.Context) error {
+	if err := idx.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("error pinging database: %w", err)
+	}
+	return nil
+}
+
+// CheckProgress returns an error when no block has been stored within window although the chain
+// advanced since the last store, which catches an indexer that is alive but stuck. A halted chain
+// is not reported as a stall.
+func (idx *Indexer) CheckProgress(window time.Duration) error {
+	idx.watchdog.mu.Lock()
+	lastStore, chainHeightAtStore := idx.watchdog.lastStore, idx.watchdog.chainHeight
+	idx.watchdog.mu.Unlock()
+
+	since := time.Since(lastStore)
+	if chainHeight := idx.ChainHeight(); since > window && chainHeight > chainHeightAtStore {
+		return fmt.Errorf("no block stored for %s while the chain advanced to height %d", since.Round(time.Second), chainHeight)
+	}
+	return nil
+}
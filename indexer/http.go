@@ -0,0 +1,102 @@
+package indexer
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/config"
+)
+
+// newHTTPClient returns the client shared by all RPC/REST calls. Idle connections are kept
+// for every concurrent fetch so backfills reuse TLS sessions instead of redialing the node.
+func newHTTPClient(concurrency int) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	resolver := &dnsCache{
+		ttl:     config.Duration("DNS_CACHE_TTL", 5*time.Minute),
+		dialer:  dialer,
+		entries: map[string]dnsEntry{},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           resolver.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          concurrency * 2,
+		MaxIdleConnsPerHost:   concurrency,
+		IdleConnTimeout:       config.Duration("HTTP_IDLE_CONN_TIMEOUT", 90*time.Second),
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   config.Duration("HTTP_TIMEOUT", 30*time.Second),
+	}
+}
+
+// dnsEntry is a cached host lookup
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// dnsCache resolves hosts at most once per ttl so repeated dials skip the DNS lookup
+type dnsCache struct {
+	ttl    time.Duration
+	dialer *net.Dialer
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+// DialContext dials addr using cached addresses for its host, trying each until one connects
+func (d *dnsCache) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || d.ttl <= 0 || net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	for _, ip := range addrs {
+		conn, err = d.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	// The cached addresses may be stale, so force a fresh lookup on the next dial
+	d.mu.Lock()
+	delete(d.entries, host)
+	d.mu.Unlock()
+	return nil, err
+}
+
+// lookup returns the cached addresses of host, resolving it when missing or expired
+func (d *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	d.mu.Lock()
+	entry, ok := d.entries[host]
+	d.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.entries[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(d.ttl)}
+	d.mu.Unlock()
+	return addrs, nil
+}
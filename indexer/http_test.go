@@ -0,0 +1,84 @@
+package indexer
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Settings of the HTTP client benchmarks
+const (
+	// benchConcurrency is the number of concurrent fetches
+	benchConcurrency = 64
+	// benchLatency is the response time of the node
+	benchLatency = time.Millisecond
+)
+
+// BenchmarkHTTPClient fetches a /status sized response from a local HTTPS node with benchConcurrency
+// concurrent requests, through the default client the indexer used before and the shared client,
+// reporting the connections dialed per request. The node is addressed by host name so the shared
+// client goes through its DNS cache.
+func BenchmarkHTTPClient(b *testing.B) {
+	for _, bc := range []struct {
+		name   string
+		client func() *http.Client
+	}{
+		{"default", func() *http.Client {
+			return &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+		}},
+		{"shared", func() *http.Client { return newHTTPClient(benchConcurrency) }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			body := `{"jsonrpc":"2.0","id":-1,"result":{"sync_info":{"latest_block_height":"14480127","catching_up":false}}}`
+			var dials atomic.Int64
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The response time of a remote node keeps benchConcurrency requests in flight
+				time.Sleep(benchLatency)
+				fmt.Fprint(w, body)
+			}))
+			server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					dials.Add(1)
+				}
+			}
+			// Connections the default client drops mid-handshake would flood the log
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			server.StartTLS()
+			defer server.Close()
+			url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/status"
+
+			client := bc.client()
+			// Trust the node's certificate, issued for example.com
+			tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+			tlsConfig.ServerName = "example.com"
+			client.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+			defer client.CloseIdleConnections()
+			parallelism := benchConcurrency / runtime.GOMAXPROCS(0)
+			if parallelism < 1 {
+				parallelism = 1
+			}
+			b.SetParallelism(parallelism)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					resp, err := client.Get(url)
+					if err != nil {
+						b.Error(err)
+						return
+					}
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+			})
+			b.ReportMetric(float64(dials.Load())/float64(b.N), "dials/op")
+		})
+	}
+}
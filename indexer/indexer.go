@@ -4,12 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/config"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
 // BlockDetails represents the structure for block data
@@ -17,65 +25,317 @@ type BlockDetails struct {
 	Height          int64           `json:"height"`
 	BlockID         string          `json:"block_id"`
 	NumTransactions int             `json:"num_transactions"`
+	NumEvents       int             `json:"num_events"`
+	TotalGasUsed    int64           `json:"total_gas_used"`
+	TotalGasWanted  int64           `json:"total_gas_wanted"`
 	Proposer        string          `json:"proposer"`
+	BlockTime       time.Time       `json:"block_time"`
+	BlockSizeBytes  int64           `json:"block_size_bytes"`
+	AppHash         string          `json:"app_hash,omitempty"`
+	TxMessageTypes  map[string]int  `json:"tx_message_types,omitempty"`
+	Events          []BlockEvent    `json:"-"`
+	ManuallyEdited  bool            `json:"manually_edited"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 	DeletedAt       sql.NullTime    `json:"deleted_at"`
 	Details         json.RawMessage `json:"details"`
 }
 
+// MarshalJSON renders deleted_at as an RFC3339 timestamp and omits it, like block_time and details, when empty
+func (b BlockDetails) MarshalJSON() ([]byte, error) {
+	type blockDetails BlockDetails
+	out := struct {
+		blockDetails
+		BlockTime *time.Time      `json:"block_time,omitempty"`
+		DeletedAt *time.Time      `json:"deleted_at,omitempty"`
+		Details   json.RawMessage `json:"details,omitempty"`
+	}{blockDetails: blockDetails(b)}
+
+	// Rows indexed before block times were stored have no block_time
+	if !b.BlockTime.IsZero() {
+		out.BlockTime = &b.BlockTime
+	}
+	if b.DeletedAt.Valid {
+		out.DeletedAt = &b.DeletedAt.Time
+	}
+	if len(b.Details) > 0 && string(b.Details) != "null" {
+		out.Details = b.Details
+	}
+
+	return json.Marshal(out)
+}
+
+// Indexing modes selectable through MODE
+const (
+	ModeFull    = "full"
+	ModeTipOnly = "tip-only"
+)
+
+// Config holds the indexer settings read from the environment
+type Config struct {
+	// RPCURLs are the RPC nodes requests are spread across, round-robin
+	RPCURLs     []string `json:"rpc_urls"`
+	RESTURL     string   `json:"rest_url"`
+	Concurrency int      `json:"concurrency"`
+	// MinConcurrency is the concurrency of indexing cycles at the tip, scaled up to Concurrency with the lag
+	MinConcurrency int `json:"min_concurrency"`
+	// ConfirmationDepth is the number of blocks below the chain tip that are not indexed yet (0 indexes up to the tip)
+	ConfirmationDepth int64 `json:"confirmation_depth"`
+	// IndexConfirmationDepth is the depth below the chain tip from which stored blocks are final and never
+	// rewritten by indexing (0 always rewrites)
+	IndexConfirmationDepth int64 `json:"index_confirmation_depth"`
+	// FullConcurrencyLag is the lag, in blocks, from which cycles run at full Concurrency (0 always does)
+	FullConcurrencyLag int64         `json:"-"`
+	Interval           time.Duration `json:"-"`
+	// MinHeight is the floor the backfill never descends below (0 for none)
+	MinHeight int64 `json:"min_height,omitempty"`
+	// MaxConsecutiveFailures is the number of consecutive RPC failures aborting an indexing cycle (0 disables)
+	MaxConsecutiveFailures int `json:"-"`
+	// FailureBackoff is the pause after an aborted indexing cycle
+	FailureBackoff time.Duration `json:"-"`
+	// FetchMode selects how blocks are fetched: "rpc" (Tendermint JSON-RPC) or "grpc" (cosmos gRPC)
+	FetchMode string `json:"fetch_mode"`
+	GRPCAddr  string `json:"grpc_addr,omitempty"`
+	// DetailsFields is the allowlist of gjson paths stored as details; empty stores the whole block results payload
+	DetailsFields []string `json:"details_fields,omitempty"`
+	// TipMode selects how new blocks are discovered: "poll" (every INDEX_INTERVAL) or "websocket" (NewBlock subscription)
+	TipMode string `json:"tip_mode"`
+	// SampleInterval indexes only the heights that are multiples of it, plus the tip (0 or 1 indexes every height)
+	SampleInterval int64 `json:"sample_interval"`
+	// Mode selects what indexing cycles cover: "full" (new blocks and the backfill) or "tip-only" (new blocks only)
+	Mode string `json:"mode"`
+	// StreamPrefetch is the number of rows streaming endpoints read ahead while writing earlier rows (0 disables read-ahead)
+	StreamPrefetch int `json:"stream_prefetch"`
+}
+
 // Indexer struct to hold dependencies
 type Indexer struct {
 	db *sql.DB
+	// readDB serves the read-only queries of the API: the read pool if configured, db otherwise
+	readDB *sql.DB
+	config Config
+	client *http.Client
+	grpc   *grpcClient
+
+	// rpc is the pool of RPC endpoints
+	rpc *endpointPool
+	// rpcSlots bounds the number of in-flight RPC requests across all indexing goroutines
+	rpcSlots chan struct{}
+	// fetches collapses concurrent fetches of the same height
+	fetches singleflight.Group
+
+	chainIDMu sync.Mutex
+	chainID   string
+
+	// chainHeight is the highest chain height reported by the node so far
+	chainHeight atomic.Int64
+	// latestHeight is the highest height LatestHeight returned so far
+	latestHeight atomic.Int64
+	// startHeight is the lowest height of the current indexing range
+	startHeight atomic.Int64
+	// backfillFrontier is the highest height the backfill still has to index
+	backfillFrontier atomic.Int64
+	// concurrency is the concurrency of the current indexing cycle
+	concurrency atomic.Int64
+	// pending is the number of in-flight block fetches and stores, awaited by Drain
+	pending  atomic.Int64
+	rate     rateTracker
+	watchdog watchdog
+
+	proposerMonitor proposerMonitor
+	operators       operatorResolver
+	latest          latestCache
+
+	// subscribed is set while new blocks arrive over the WebSocket subscription
+	subscribed       atomic.Bool
+	subscriptionLost chan struct{}
+}
+
+// NewIndexer creates a new Indexer instance writing to db, reading its settings from env vars.
+// The read-only queries of the API go to readDB unless it is nil.
+func NewIndexer(db, readDB *sql.DB) *Indexer {
+	cfg := Config{
+		RESTURL:     strings.TrimSuffix(config.String("REST_URL", "https://rest.omniflix.network"), "/"),
+		Concurrency: config.Int("INDEX_CONCURRENCY", 100),
+
+		MinConcurrency:         config.Int("INDEX_MIN_CONCURRENCY", 5),
+		FullConcurrencyLag:     config.Int64("INDEX_FULL_CONCURRENCY_LAG", 1000),
+		IndexConfirmationDepth: config.Int64("INDEX_CONFIRMATION_DEPTH", 0),
+		ConfirmationDepth:      config.Int64("CONFIRMATION_DEPTH", 0),
+		Interval:               config.Duration("INDEX_INTERVAL", 2*time.Second),
+		FetchMode:              strings.ToLower(config.String("FETCH_MODE", FetchModeRPC)),
+		GRPCAddr:               config.String("GRPC_ADDR", "grpc.omniflix.network:443"),
+		TipMode:                strings.ToLower(config.String("TIP_MODE", TipModePoll)),
+		Mode:                   strings.ToLower(config.String("MODE", ModeFull)),
+		SampleInterval:         config.Int64("SAMPLE_INTERVAL", 0),
+		MinHeight:              config.Int64("MIN_INDEX_HEIGHT", 0),
+		StreamPrefetch:         config.Int("STREAM_PREFETCH_ROWS", 500),
+
+		DetailsFields: config.List("DETAILS_FIELDS"),
+
+		MaxConsecutiveFailures: config.Int("INDEX_MAX_CONSECUTIVE_FAILURES", 50),
+		FailureBackoff:         config.Duration("INDEX_FAILURE_BACKOFF", time.Minute),
+	}
+	for _, url := range config.List("RPC_URL") {
+		cfg.RPCURLs = append(cfg.RPCURLs, strings.TrimSuffix(url, "/"))
+	}
+	if len(cfg.RPCURLs) == 0 {
+		cfg.RPCURLs = []string{"https://rpc.omniflix.network"}
+	}
+	if cfg.FetchMode != FetchModeRPC && cfg.FetchMode != FetchModeGRPC {
+		log.Printf("Unknown FETCH_MODE %q, using %s", cfg.FetchMode, FetchModeRPC)
+		cfg.FetchMode = FetchModeRPC
+	}
+	if cfg.FetchMode != FetchModeGRPC {
+		cfg.GRPCAddr = ""
+	}
+	if cfg.MinConcurrency < 1 {
+		cfg.MinConcurrency = 1
+	}
+	if cfg.MinConcurrency > cfg.Concurrency {
+		cfg.MinConcurrency = cfg.Concurrency
+	}
+	if cfg.Mode != ModeFull && cfg.Mode != ModeTipOnly {
+		log.Printf("Unknown MODE %q, using %s", cfg.Mode, ModeFull)
+		cfg.Mode = ModeFull
+	}
+	if cfg.TipMode != TipModePoll && cfg.TipMode != TipModeWebsocket {
+		log.Printf("Unknown TIP_MODE %q, using %s", cfg.TipMode, TipModePoll)
+		cfg.TipMode = TipModePoll
+	}
+
+	idx := &Indexer{
+		db:       db,
+		readDB:   db,
+		config:   cfg,
+		client:   newHTTPClient(cfg.Concurrency),
+		grpc:     &grpcClient{addr: cfg.GRPCAddr, insecure: config.Bool("GRPC_INSECURE", false)},
+		rpcSlots: make(chan struct{}, cfg.Concurrency),
+		rpc:      newEndpointPool(cfg.RPCURLs, config.Int("RPC_ENDPOINT_MAX_FAILURES", 3), config.Duration("RPC_ENDPOINT_COOLDOWN", 30*time.Second)),
+		chainID:  config.String("CHAIN_ID", ""),
+
+		subscriptionLost: make(chan struct{}, 1),
+		proposerMonitor:  proposerMonitor{url: config.String("NEW_PROPOSER_WEBHOOK_URL", "")},
+		operators:        operatorResolver{ttl: config.Duration("VALIDATOR_CACHE_TTL", 10*time.Minute)},
+		latest:           latestCache{ttl: config.Duration("LATEST_BLOCKS_CACHE_TTL", 2*time.Second)},
+	}
+	if readDB != nil {
+		idx.readDB = readDB
+	}
+	idx.watchdog.lastStore = time.Now()
+
+	return idx
+}
+
+// ChainHeight returns the highest chain height reported by the node so far, or 0 if unknown
+func (idx *Indexer) ChainHeight() int64 {
+	return idx.chainHeight.Load()
+}
+
+// RunChainHeightRefresh refreshes the chain height every CHAIN_HEIGHT_REFRESH_INTERVAL (disabled when 0)
+// until ctx is cancelled, so it stays current during long indexing cycles, which only poll it at their start
+func (idx *Indexer) RunChainHeightRefresh(ctx context.Context) {
+	interval := config.Duration("CHAIN_HEIGHT_REFRESH_INTERVAL", 10*time.Second)
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Successful fetches record the height through observeChainHeight
+		if _, err := idx.GetLatestBlockHeightFromREST(); err != nil {
+			log.Printf("Error refreshing chain height: %v", err)
+		}
+	}
 }
 
-// NewIndexer creates a new Indexer instance
-func NewIndexer(db *sql.DB) *Indexer {
-	return &Indexer{
-		db: db,
+// observeChainHeight records a chain height reported by the node, keeping the highest one
+func (idx *Indexer) observeChainHeight(height int64) {
+	for {
+		current := idx.chainHeight.Load()
+		if height <= current || idx.chainHeight.CompareAndSwap(current, height) {
+			return
+		}
 	}
 }
 
+// acquireRPC blocks until an RPC request slot is available or ctx is done
+func (idx *Indexer) acquireRPC(ctx context.Context) error {
+	select {
+	case idx.rpcSlots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseRPC releases a slot taken by acquireRPC
+func (idx *Indexer) releaseRPC() {
+	<-idx.rpcSlots
+}
+
+// Config returns the indexer settings
+func (idx *Indexer) Config() Config {
+	return idx.config
+}
+
+// BlockSource tells where GetBlockDetails found a block
+type BlockSource string
+
+// Sources of GetBlockDetails
+const (
+	// SourceDB is a block read from the database
+	SourceDB BlockSource = "db"
+	// SourceRPC is a block fetched from the blockchain on demand
+	SourceRPC BlockSource = "rpc"
+)
+
 // GetBlockDetails fetches block details from the database if available,
 // otherwise fetches from the blockchain and stores it in the database.
-func (idx *Indexer) GetBlockDetails(height int64) (*BlockDetails, error) {
+// It also returns which of the two the block came from.
+func (idx *Indexer) GetBlockDetails(height int64) (*BlockDetails, BlockSource, error) {
 	// 1. Try fetching from Postgres first
-	var blockDetails BlockDetails
-	err := idx.db.QueryRow("SELECT block_height, block_id,proposer_address, num_transactions, created_at, updated_at, deleted_at, details FROM blocks WHERE block_height = $1", height).Scan(
-		&blockDetails.Height,
-		&blockDetails.BlockID,
-		&blockDetails.Proposer,
-		&blockDetails.NumTransactions,
-		&blockDetails.CreatedAt,
-		&blockDetails.UpdatedAt,
-		&blockDetails.DeletedAt,
-		&blockDetails.Details,
-	)
+	blockDetails, err := scanBlock(idx.readDB.QueryRow("SELECT "+blockColumns+" FROM blocks WHERE block_height = $1", height))
+	if err == nil {
+		return &blockDetails, SourceDB, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, "", fmt.Errorf("error fetching block details from database: %w", err)
+	}
+
+	// 2. If not found in Postgres, fetch from blockchain
+	blockDetails, err = idx.FetchAndStoreBlockDetails(context.Background(), height)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// 2. If not found in Postgres, fetch from blockchain
-			blockDetails, err = idx.FetchAndStoreBlockDetails(height)
-			if err != nil {
-				return nil, fmt.Errorf("error fetching and storing block details: %w", err)
-			}
-		} else {
-			return nil, fmt.Errorf("error fetching block details from database: %w", err)
-		}
+		return nil, "", fmt.Errorf("error fetching and storing block details: %w", err)
 	}
 
-	return &blockDetails, nil
+	return &blockDetails, SourceRPC, nil
 }
 
-// StartIndexing starts the continuous indexing process with concurrency
-func (idx *Indexer) StartIndexing(minBlockHeight, maxBlockHeight int64) {
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 100) // Limit concurrency to 100 goroutines
+// StartIndexing starts the continuous indexing process with concurrency. The backfill resumes from
+// the frontier stored in indexer_state, after indexing the blocks produced since the previous run.
+// It returns ErrCycleAborted when sustained RPC failures cut the cycle short, and ctx.Err() once ctx
+// is cancelled, without waiting for in-flight blocks (see Drain).
+func (idx *Indexer) StartIndexing(ctx context.Context, minBlockHeight, maxBlockHeight int64) error {
+	// Never descend below MIN_INDEX_HEIGHT
+	if minBlockHeight < idx.config.MinHeight {
+		minBlockHeight = idx.config.MinHeight
+	}
+	idx.startHeight.Store(minBlockHeight)
 
-	// Fetch the latest block height
-	latestHeight, err := idx.GetLatestBlockHeightFromREST()
+	// Fetch the latest block height, keeping CONFIRMATION_DEPTH blocks below it unindexed
+	latestHeight, err := idx.LatestHeight()
 	if err != nil {
 		log.Printf("Error fetching latest block height: %v", err)
 	}
+	latestHeight = idx.ConfirmedHeight(latestHeight)
 
 	// If latestHeight is greater than maxBlockHeight, update maxBlockHeight
 	if latestHeight > maxBlockHeight {
@@ -83,81 +343,340 @@ func (idx *Indexer) StartIndexing(minBlockHeight, maxBlockHeight int64) {
 		maxBlockHeight = latestHeight
 	}
 
-	for currentHeight := maxBlockHeight; currentHeight >= minBlockHeight; currentHeight-- {
+	frontier, top, found, err := idx.loadBackfill()
+	if err != nil {
+		log.Printf("Error loading backfill checkpoint, indexing the whole range: %v", err)
+	}
+	if !found {
+		frontier, top = maxBlockHeight, maxBlockHeight
+		// The checkpoint needs both keys, and MODE=tip-only never saves the frontier afterwards
+		if err := idx.setState(stateBackfillFrontier, frontier); err != nil {
+			log.Printf("Error saving backfill frontier: %v", err)
+		}
+		if err := idx.setState(stateBackfillTop, top); err != nil {
+			log.Printf("Error saving backfill top: %v", err)
+		}
+	}
+
+	// MODE=tip-only never descends below the checkpoint
+	backfill := idx.config.Mode != ModeTipOnly
+
+	// Scale the concurrency with the number of blocks left to index
+	lag := maxBlockHeight - top
+	if backfill && frontier >= minBlockHeight {
+		lag += frontier - minBlockHeight + 1
+	}
+	concurrency := idx.adaptConcurrency(lag)
+
+	// Index the blocks produced since the previous run, then extend the checkpoint to cover them
+	if top < maxBlockHeight {
+		if err := idx.indexRange(ctx, maxBlockHeight, top+1, concurrency, nil); err != nil {
+			return err
+		}
+		// The tip is indexed whatever SAMPLE_INTERVAL, so the latest indexed height stays current
+		if !idx.sampled(maxBlockHeight) {
+			if _, err := idx.FetchAndStoreBlockDetails(ctx, maxBlockHeight); err != nil && ctx.Err() == nil {
+				log.Printf("Error indexing tip block %d: %v", maxBlockHeight, err)
+			}
+		}
+		if err := idx.setState(stateBackfillTop, maxBlockHeight); err != nil {
+			log.Printf("Error saving backfill top: %v", err)
+		}
+	}
+
+	// Resume the backfill from the frontier
+	if backfill && frontier >= minBlockHeight {
+		if found {
+			log.Printf("Resuming backfill from height %d", frontier)
+		}
+		tracker := idx.newFrontierTracker(frontier)
+		err := idx.indexRange(ctx, frontier, minBlockHeight, concurrency, tracker.complete)
+		tracker.flush()
+		if err != nil {
+			return err
+		}
+		if idx.backfillFrontier.Load() < minBlockHeight {
+			log.Printf("Backfill complete down to height %d", minBlockHeight)
+		}
+		return nil
+	}
+	idx.backfillFrontier.Store(frontier)
+
+	return nil
+}
+
+// indexRange indexes the heights from 'from' down to 'to' (inclusive), concurrency at a time, calling done,
+// when set, once each height is indexed or definitively unavailable. Heights failing transiently are
+// not reported, so the backfill frontier holds there and a later cycle retries them.
+// It stops launching heights and returns ErrCycleAborted once the circuit breaker opens, or ctx.Err()
+// once ctx is cancelled; in the latter case in-flight heights are left to finish in the background.
+func (idx *Indexer) indexRange(ctx context.Context, from, to int64, concurrency int, done func(height int64)) error {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency) // Limit concurrency to the cycle's concurrency goroutines
+	breaker := &circuitBreaker{threshold: int64(idx.config.MaxConsecutiveFailures)}
+
+launch:
+	for currentHeight := from; currentHeight >= to && !breaker.isOpen(); currentHeight-- {
+		// Heights left out by SAMPLE_INTERVAL count as processed
+		if !idx.sampled(currentHeight) {
+			if done != nil {
+				done(currentHeight)
+			}
+			continue
+		}
+
+		// Acquire a semaphore slot, unless shutting down
+		select {
+		case <-ctx.Done():
+			break launch
+		case semaphore <- struct{}{}:
+		}
 		wg.Add(1)
-		semaphore <- struct{}{} // Acquire a semaphore slot
+		finish := idx.beginWork()
 
 		go func(height int64) {
 			defer wg.Done()
+			defer finish()
 			defer func() { <-semaphore }() // Release the semaphore slot
 
-			_, err := idx.FetchAndStoreBlockDetails(height)
-			if err != nil {
+			// Heights launched just before shutdown are left for the next run
+			if ctx.Err() != nil {
+				return
+			}
+			_, err := idx.FetchAndStoreBlockDetails(ctx, height)
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			if breaker.record(err) {
+				log.Printf("Aborting indexing cycle after %d consecutive RPC failures, last at block %d: %v", breaker.threshold, height, err)
+			} else if err != nil && !breaker.isOpen() {
 				log.Printf("Error indexing block %d: %v", height, err)
 			}
+			// Pruned heights never become available; they show up in /gaps/ranges
+			if done != nil && (err == nil || !isTransient(err)) {
+				done(height)
+			}
 		}(currentHeight)
 	}
 
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	wg.Wait()
+
+	if breaker.isOpen() {
+		return ErrCycleAborted
+	}
+	return nil
 }
 
-// FetchAndStoreBlockDetails fetches and stores block details with timestamps (using only RPC)
-func (idx *Indexer) FetchAndStoreBlockDetails(height int64) (BlockDetails, error) {
+// sampled reports whether indexing cycles index height under SAMPLE_INTERVAL
+func (idx *Indexer) sampled(height int64) bool {
+	return idx.config.SampleInterval <= 1 || height%idx.config.SampleInterval == 0
+}
+
+// FetchAndStoreBlockDetails fetches and stores block details with timestamps (using only RPC).
+// Blocks deeper than INDEX_CONFIRMATION_DEPTH below the chain tip are final, so an existing row of
+// such a block is kept as is. Concurrent calls for the same height share a single fetch, which
+// cancelling ctx aborts.
+func (idx *Indexer) FetchAndStoreBlockDetails(ctx context.Context, height int64) (BlockDetails, error) {
+	return idx.sharedFetchAndStore(ctx, strconv.FormatInt(height, 10), height, false)
+}
+
+// RefreshBlockDetails fetches block details and stores them over the existing row, whatever its depth,
+// to fix a stored block. Manually edited rows are still kept.
+func (idx *Indexer) RefreshBlockDetails(height int64) (BlockDetails, error) {
+	return idx.sharedFetchAndStore(context.Background(), "refresh:"+strconv.FormatInt(height, 10), height, true)
+}
+
+// sharedFetchAndStore runs fetchAndStoreBlockDetails, sharing it between concurrent calls with the same key
+func (idx *Indexer) sharedFetchAndStore(ctx context.Context, key string, height int64, overwrite bool) (BlockDetails, error) {
+	leader := false
+	result, err, _ := idx.fetches.Do(key, func() (interface{}, error) {
+		leader = true
+		fetchInflight.Inc()
+		defer fetchInflight.Dec()
+		return idx.fetchAndStoreBlockDetails(ctx, height, overwrite)
+	})
+	if !leader {
+		fetchDeduplicated.Inc()
+	}
+	if err != nil {
+		return BlockDetails{}, err
+	}
+
+	return result.(BlockDetails), nil
+}
+
+// blockUpsert is the conflict clause updating an existing block row; manually corrected rows are never
+// overwritten by automatic indexing
+const blockUpsert = `DO UPDATE
+			SET block_id = EXCLUDED.block_id,
+				proposer_address = EXCLUDED.proposer_address,
+				block_time = EXCLUDED.block_time,
+				num_transactions = EXCLUDED.num_transactions,
+				num_events = EXCLUDED.num_events,
+				total_gas_used = EXCLUDED.total_gas_used,
+				total_gas_wanted = EXCLUDED.total_gas_wanted,
+				block_size_bytes = EXCLUDED.block_size_bytes,
+				app_hash = EXCLUDED.app_hash,
+				tx_message_types = EXCLUDED.tx_message_types,
+				details = EXCLUDED.details,
+				updated_at = EXCLUDED.updated_at
+			WHERE blocks.manually_edited IS NOT TRUE`
+
+// ConfirmedHeight returns the highest height to index given the latest chain height, CONFIRMATION_DEPTH below it
+func (idx *Indexer) ConfirmedHeight(latestHeight int64) int64 {
+	if confirmed := latestHeight - idx.config.ConfirmationDepth; confirmed > 0 {
+		return confirmed
+	}
+	return 0
+}
+
+// isFinal reports whether height is deeper than the confirmation depth below the known chain tip
+func (idx *Indexer) isFinal(height int64) bool {
+	depth, chainHeight := idx.config.IndexConfirmationDepth, idx.ChainHeight()
+	return depth > 0 && chainHeight > 0 && height <= chainHeight-depth
+}
+
+// fetchAndStoreBlockDetails fetches block details and stores them in the background, over an existing
+// row when overwrite is set or the block is not final yet
+func (idx *Indexer) fetchAndStoreBlockDetails(ctx context.Context, height int64, overwrite bool) (BlockDetails, error) {
 	var (
 		blockDetails BlockDetails
 		err          error
 	)
 
-	blockDetails, err = idx.getBlockResults(height)
+	blockDetails, err = idx.getBlockResults(ctx, height)
 	if err != nil {
 		return BlockDetails{}, fmt.Errorf("error getting block details: %w", err)
 	}
 
-	// Store blockDetails in the database with timestamps
+	// Store blockDetails in the database in the background
+	finish := idx.beginWork()
 	go func() {
-		ctx := context.Background()
+		defer finish()
+		if err := idx.storeBlockDetails(context.Background(), blockDetails, overwrite); err != nil {
+			log.Printf("Error storing block %d: %v", height, err)
+		}
+	}()
+
+	return blockDetails, nil
+}
+
+// fetchAndStoreBlockDetailsNow is fetchAndStoreBlockDetails storing the block before returning,
+// so callers know whether it was written
+func (idx *Indexer) fetchAndStoreBlockDetailsNow(ctx context.Context, height int64, overwrite bool) (BlockDetails, error) {
+	blockDetails, err := idx.getBlockResults(ctx, height)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error getting block details: %w", err)
+	}
+	if err := idx.storeBlockDetails(ctx, blockDetails, overwrite); err != nil {
+		return BlockDetails{}, err
+	}
+	return blockDetails, nil
+}
+
+// storeBlockDetails stores fetched block details with timestamps, over an existing row when overwrite
+// is set or the block is not final yet
+func (idx *Indexer) storeBlockDetails(ctx context.Context, blockDetails BlockDetails, overwrite bool) error {
+	height := blockDetails.Height
 
-		// Convert Details to JSON string
-		detailsJSON, err := json.Marshal(blockDetails.Details)
+	// Convert Details to JSON string
+	detailsJSON, err := json.Marshal(blockDetails.Details)
+	if err != nil {
+		return fmt.Errorf("error marshaling details to JSON: %w", err)
+	}
+	messageTypesJSON, err := json.Marshal(blockDetails.TxMessageTypes)
+	if err != nil {
+		return fmt.Errorf("error marshaling tx message types to JSON: %w", err)
+	}
+
+	// Refreshing an indexed block only rewrites the columns that changed
+	if overwrite {
+		changed, found, err := idx.updateChangedColumns(ctx, blockDetails, messageTypesJSON, detailsJSON)
 		if err != nil {
-			log.Printf("Error marshaling details to JSON: %v", err)
-			return
+			return fmt.Errorf("error refreshing block: %w", err)
+		}
+		if found {
+			if len(changed) == 0 {
+				return nil
+			}
+			log.Printf("Refreshed block %d, updated %s", height, strings.Join(changed, ", "))
+			idx.finishStore(ctx, blockDetails)
+			return nil
 		}
+	}
 
-		currentTime := time.Now()
-		_, err = idx.db.ExecContext(ctx, `
-			INSERT INTO blocks (block_height, block_id, proposer_address, num_transactions, details, created_at, updated_at, deleted_at) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7, NULL)
-			ON CONFLICT (block_height) DO UPDATE 
-			SET block_id = EXCLUDED.block_id,
-				proposer_address = EXCLUDED.proposer_address,
-				num_transactions = EXCLUDED.num_transactions,
-				details = EXCLUDED.details,
-				updated_at = EXCLUDED.updated_at`,
-			height, blockDetails.BlockID, blockDetails.Proposer, blockDetails.NumTransactions, detailsJSON, currentTime, currentTime)
-		if err != nil {
-			log.Printf("Error storing block data in database: %v", err)
+	currentTime := time.Now()
+	// Final blocks are immutable, so re-indexing them only inserts missing rows
+	conflict := blockUpsert
+	insertOnly := !overwrite && idx.isFinal(height)
+	if insertOnly {
+		conflict = "DO NOTHING"
+	}
+	result, err := idx.execWithRetry(ctx, `
+		INSERT INTO blocks (block_height, block_id, proposer_address, block_time, num_transactions, num_events, total_gas_used, total_gas_wanted, block_size_bytes, app_hash, tx_message_types, details, created_at, updated_at, deleted_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULL)
+		ON CONFLICT (block_height) `+conflict,
+		height, blockDetails.BlockID, blockDetails.Proposer, nullTime(blockDetails.BlockTime), blockDetails.NumTransactions, blockDetails.NumEvents, blockDetails.TotalGasUsed, blockDetails.TotalGasWanted, blockDetails.BlockSizeBytes, blockDetails.AppHash, messageTypesJSON, detailsJSON, currentTime, currentTime)
+	if err != nil {
+		return fmt.Errorf("error storing block data in database: %w", err)
+	}
+	if stored, err := result.RowsAffected(); err == nil && stored == 0 {
+		if !insertOnly {
+			log.Printf("Skipping manually edited block %d", height)
 		}
-	}()
+		return nil
+	}
+	idx.finishStore(ctx, blockDetails)
+	return nil
+}
 
-	return blockDetails, nil
+// finishStore stores the events of a block whose row was just written and records the store
+func (idx *Indexer) finishStore(ctx context.Context, blockDetails BlockDetails) {
+	idx.latest.invalidate(blockDetails.Height)
+	if err := idx.storeEvents(ctx, blockDetails.Height, blockDetails.Events); err != nil {
+		log.Printf("Error storing events of block %d: %v", blockDetails.Height, err)
+		return
+	}
+	idx.checkProposer(ctx, blockDetails)
+	idx.rate.record()
+	idx.watchdog.recordStore(idx.ChainHeight())
 }
 
-// GetLatestBlockHeight fetches the latest block height
+// GetLatestBlockHeight fetches the latest block height, failing over across RPC endpoints
 func (idx *Indexer) GetLatestBlockHeight() (int64, error) {
-	url := "https://rpc.omniflix.network/status"
-	resp, err := http.Get(url)
+	var height int64
+	err := idx.rpc.do(func(baseURL string) error {
+		var err error
+		height, err = getLatestBlockHeightFrom(idx.client, baseURL)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	idx.observeChainHeight(height)
+
+	return height, nil
+}
+
+// getLatestBlockHeightFrom fetches the latest block height from the /status endpoint of the RPC node at baseURL
+func getLatestBlockHeightFrom(client *http.Client, baseURL string) (int64, error) {
+	url := baseURL + "/status"
+	resp, err := client.Get(url)
 	if err != nil {
 		return 0, fmt.Errorf("error fetching status: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSON(resp.Body, &result); err != nil {
 		return 0, fmt.Errorf("error decoding status: %w", err)
 	}
 
-	syncInfo, ok := result["result"].(map[string]interface{})["sync_info"].(map[string]interface{})
+	status, _ := result["result"].(map[string]interface{})
+	syncInfo, ok := status["sync_info"].(map[string]interface{})
 	if !ok {
 		return 0, fmt.Errorf("sync_info not found in response")
 	}
@@ -173,11 +692,73 @@ func (idx *Indexer) GetLatestBlockHeight() (int64, error) {
 	return height, nil
 }
 
-// GetLatestBlockHeightFromREST fetches the latest block height from the REST API
+// LatestHeight returns the latest chain height, falling back to the highest indexed height
+// when neither the REST API nor the RPC can be reached
+func (idx *Indexer) LatestHeight() (int64, error) {
+	height, err := idx.GetLatestBlockHeightFromREST()
+	if err != nil {
+		latest, dbErr := idx.GetLatestIndexedBlock()
+		if dbErr != nil {
+			return 0, fmt.Errorf("%w; no indexed block to fall back to: %v", err, dbErr)
+		}
+		log.Printf("Error fetching latest block height, falling back to the highest indexed height %d: %v", latest.Height, err)
+		// The indexed tip trails the chain, which is no regression of the node
+		if previous := idx.latestHeight.Load(); previous > latest.Height {
+			return previous, nil
+		}
+		return latest.Height, nil
+	}
+
+	return idx.advanceLatestHeight(height), nil
+}
+
+// advanceLatestHeight returns the highest of height and the heights returned before. Behind a load
+// balanced pool of nodes the reported tip can go backward when a request lands on a lagging node;
+// such regressions are logged and ignored so the indexing range never shrinks.
+func (idx *Indexer) advanceLatestHeight(height int64) int64 {
+	for {
+		previous := idx.latestHeight.Load()
+		if height < previous {
+			latestHeightRegressions.Inc()
+			log.Printf("Latest block height went backward from %d to %d, keeping %d", previous, height, previous)
+			return previous
+		}
+		if idx.latestHeight.CompareAndSwap(previous, height) {
+			return height
+		}
+	}
+}
+
+// GetLatestBlockHeightFromREST fetches the latest block height from the REST API,
+// falling back to the RPC /status endpoint when the REST API fails
 func (idx *Indexer) GetLatestBlockHeightFromREST() (int64, error) {
-	url := "https://rest.omniflix.network/cosmos/base/tendermint/v1beta1/blocks/latest"
+	height, err := idx.getLatestBlockHeightREST()
+	if err == nil {
+		return height, nil
+	}
+
+	height, rpcErr := idx.GetLatestBlockHeight()
+	if rpcErr != nil {
+		return 0, fmt.Errorf("%w; RPC fallback: %v", err, rpcErr)
+	}
 
-	resp, err := http.Get(url)
+	return height, nil
+}
+
+// latestBlockResponse is the part of the REST /cosmos/base/tendermint/v1beta1/blocks/latest response read by the indexer
+type latestBlockResponse struct {
+	Block *struct {
+		Header *struct {
+			Height string `json:"height"`
+		} `json:"header"`
+	} `json:"block"`
+}
+
+// getLatestBlockHeightREST fetches the latest block height from the REST API
+func (idx *Indexer) getLatestBlockHeightREST() (int64, error) {
+	url := idx.config.RESTURL + "/cosmos/base/tendermint/v1beta1/blocks/latest"
+
+	resp, err := idx.client.Get(url)
 	if err != nil {
 		return 0, fmt.Errorf("error fetching latest block from REST API: %w", err)
 	}
@@ -187,60 +768,62 @@ func (idx *Indexer) GetLatestBlockHeightFromREST() (int64, error) {
 		return 0, fmt.Errorf("REST API request failed with status code: %d", resp.StatusCode)
 	}
 
-	var result map[string]interface{}
+	var result latestBlockResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return 0, fmt.Errorf("error decoding latest block from REST API: %w", err)
 	}
-
-	// Extract block height (adapt based on actual JSON structure)
-	block, ok := result["block"].(map[string]interface{})
-	if !ok {
-		return 0, fmt.Errorf("invalid REST API response: 'block' field not found")
-	}
-	header, ok := block["header"].(map[string]interface{})
-	if !ok {
-		return 0, fmt.Errorf("invalid REST API response: 'header' field not found")
+	if result.Block == nil || result.Block.Header == nil {
+		return 0, fmt.Errorf("invalid REST API response: 'block.header' field not found")
 	}
-	heightStr, ok := header["height"].(string)
-	if !ok {
+	if result.Block.Header.Height == "" {
 		return 0, fmt.Errorf("invalid REST API response: 'height' field not found")
 	}
 
-	height, err := strconv.ParseInt(heightStr, 10, 64)
+	height, err := strconv.ParseInt(result.Block.Header.Height, 10, 64)
 	if err != nil {
 		return 0, fmt.Errorf("error parsing block height: %w", err)
 	}
+	idx.observeChainHeight(height)
 
 	return height, nil
 }
 
-// getBlockResults fetches block results from the RPC /block_results endpoint
-func (idx *Indexer) getBlockResults(height int64) (BlockDetails, error) {
-	url := fmt.Sprintf("https://rpc.omniflix.network/block_results?height=%d", height)
-	resp, err := http.Get(url)
-	if err != nil {
-		return BlockDetails{}, fmt.Errorf("error fetching block results: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return BlockDetails{}, fmt.Errorf("error decoding block results: %w", err)
-	}
-
-	resultResult, ok := result["result"].(map[string]interface{})
-	if !ok || resultResult == nil {
-		return BlockDetails{}, fmt.Errorf("invalid or missing 'result' field in /block_results API response")
-	}
+// getBlockResults fetches block results from the RPC /block_results endpoint,
+// fetching the /block endpoint concurrently for the fields block_results lacks
+func (idx *Indexer) getBlockResults(ctx context.Context, height int64) (BlockDetails, error) {
+	var (
+		resultResult map[string]interface{}
+		blockData    BlockDetails
+	)
 
-	// If block_id is not found in /block_results, try fetching it from /block
-	blockData, err := idx.getBlock(height)
-	if err != nil {
-		return BlockDetails{}, fmt.Errorf("error fetching block_id from /block: %w", err)
+	var g errgroup.Group
+	g.Go(func() error {
+		var err error
+		resultResult, err = idx.fetchBlockResults(ctx, height)
+		return err
+	})
+	// block_id and proposer are not part of /block_results, so they come from /block (or gRPC)
+	g.Go(func() error {
+		var err error
+		if idx.config.FetchMode == FetchModeGRPC {
+			blockData, err = idx.getBlockGRPC(ctx, height)
+		} else {
+			blockData, err = idx.getBlock(ctx, height)
+		}
+		if err != nil {
+			return fmt.Errorf("error fetching block_id from /block: %w", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return BlockDetails{}, err
 	}
-	// Use the block_id and proposer from /block response
+	// Use the block_id, proposer, time, size, app hash and message types from /block response
 	blockID := blockData.BlockID
 	proposer := blockData.Proposer
+	blockTime := blockData.BlockTime
+	blockSize := blockData.BlockSizeBytes
+	messageTypes := blockData.TxMessageTypes
 
 	txsResults, ok := resultResult["txs_results"]
 	if !ok {
@@ -248,38 +831,162 @@ func (idx *Indexer) getBlockResults(height int64) (BlockDetails, error) {
 	}
 
 	numTransactions := 0
+	var gasUsed, gasWanted int64
 	switch txs := txsResults.(type) {
 	case []interface{}:
 		numTransactions = len(txs)
+		for _, txResult := range txs {
+			txResultMap, _ := txResult.(map[string]interface{})
+			gasUsed += parseGas(txResultMap["gas_used"])
+			gasWanted += parseGas(txResultMap["gas_wanted"])
+		}
 	case nil:
 		numTransactions = 0
 	default:
 		return BlockDetails{}, fmt.Errorf("unexpected type for txs_results: %T", txs)
 	}
 
+	events, err := extractEvents(resultResult)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error extracting events from block results: %w", err)
+	}
+	numEvents := countBlockEvents(events)
+
+	// Keep the block results payload, or the DETAILS_FIELDS extracted from it, as the block details
+	details, err := idx.encodeDetails(resultResult)
+	if err != nil {
+		return BlockDetails{}, err
+	}
+
 	blockDetails := BlockDetails{
 		Height:          height,
 		BlockID:         blockID,
 		Proposer:        proposer,
+		BlockTime:       blockTime,
 		NumTransactions: numTransactions,
-		// ... extract details, etc. from resultResult
+		NumEvents:       numEvents,
+		TotalGasUsed:    gasUsed,
+		TotalGasWanted:  gasWanted,
+		BlockSizeBytes:  blockSize,
+		AppHash:         blockData.AppHash,
+		TxMessageTypes:  messageTypes,
+		Events:          events,
+		Details:         details,
 	}
 	return blockDetails, nil
 }
 
-// getBlock fetches block data from the RPC /block endpoint (for extracting block_id and proposer)
-func (idx *Indexer) getBlock(height int64) (BlockDetails, error) {
-	url := fmt.Sprintf("https://rpc.omniflix.network/block?height=%d", height)
-	resp, err := http.Get(url)
+// parseGas reads a gas_used/gas_wanted value of a tx result, which the RPC encodes as a string
+func parseGas(v interface{}) int64 {
+	switch gas := v.(type) {
+	case string:
+		n, _ := strconv.ParseInt(gas, 10, 64)
+		return n
+	case json.Number:
+		n, _ := gas.Int64()
+		return n
+	default:
+		return 0
+	}
+}
+
+// decodeJSON decodes an RPC response into v, keeping the numbers of generic values as json.Number
+// so integers above 2^53 survive decoding and re-encoding as block details
+func decodeJSON(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// fetchBlockResults fetches the 'result' object of the RPC /block_results endpoint, failing over across RPC endpoints
+func (idx *Indexer) fetchBlockResults(ctx context.Context, height int64) (map[string]interface{}, error) {
+	if err := idx.acquireRPC(ctx); err != nil {
+		return nil, err
+	}
+	defer idx.releaseRPC()
+
+	var resultResult map[string]interface{}
+	err := idx.rpc.do(func(baseURL string) error {
+		var err error
+		resultResult, err = fetchBlockResultsFrom(ctx, idx.client, baseURL, height)
+		return err
+	})
+	return resultResult, err
+}
+
+// fetchBlockResultsFrom fetches the 'result' object of the /block_results endpoint of the RPC node at baseURL
+func fetchBlockResultsFrom(ctx context.Context, client *http.Client, baseURL string, height int64) (map[string]interface{}, error) {
+	url := fmt.Sprintf("%s/block_results?height=%d", baseURL, height)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating block results request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching block results: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("error fetching block results: %w", ErrRateLimited)
+	}
+
+	var result map[string]interface{}
+	if err := decodeJSON(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("error decoding block results: %w", err)
+	}
+	if rpcErr, ok := result["error"]; ok && rpcErr != nil {
+		return nil, fmt.Errorf("error fetching block results: %w", rpcError(rpcErr))
+	}
+
+	resultResult, ok := result["result"].(map[string]interface{})
+	if !ok || resultResult == nil {
+		return nil, fmt.Errorf("invalid or missing 'result' field in /block_results API response")
+	}
+	return resultResult, nil
+}
+
+// getBlock fetches block data from the RPC /block endpoint (for extracting block_id, proposer and block size),
+// failing over across RPC endpoints
+func (idx *Indexer) getBlock(ctx context.Context, height int64) (BlockDetails, error) {
+	if err := idx.acquireRPC(ctx); err != nil {
+		return BlockDetails{}, err
+	}
+	defer idx.releaseRPC()
+
+	var blockDetails BlockDetails
+	err := idx.rpc.do(func(baseURL string) error {
+		var err error
+		blockDetails, err = getBlockFrom(ctx, idx.client, baseURL, height)
+		return err
+	})
+	return blockDetails, err
+}
+
+// getBlockFrom fetches block data from the /block endpoint of the RPC node at baseURL
+func getBlockFrom(ctx context.Context, client *http.Client, baseURL string, height int64) (BlockDetails, error) {
+	url := fmt.Sprintf("%s/block?height=%d", baseURL, height)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error creating block request: %w", err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return BlockDetails{}, fmt.Errorf("error fetching block from RPC: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return BlockDetails{}, fmt.Errorf("error fetching block from RPC: %w", ErrRateLimited)
+	}
+
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSON(resp.Body, &result); err != nil {
 		return BlockDetails{}, fmt.Errorf("error decoding block from RPC: %w", err)
 	}
+	if rpcErr, ok := result["error"]; ok && rpcErr != nil {
+		return BlockDetails{}, fmt.Errorf("error fetching block from RPC: %w", rpcError(rpcErr))
+	}
 
 	resultResult, ok := result["result"].(map[string]interface{})
 	if !ok || resultResult == nil {
@@ -291,14 +998,53 @@ func (idx *Indexer) getBlock(height int64) (BlockDetails, error) {
 		return BlockDetails{}, fmt.Errorf("error extracting block_id from /block response")
 	}
 
-	proposer, ok := resultResult["block"].(map[string]interface{})["header"].(map[string]interface{})["proposer_address"].(string)
+	block, ok := resultResult["block"].(map[string]interface{})
+	if !ok {
+		return BlockDetails{}, fmt.Errorf("error extracting block from /block response")
+	}
+
+	header, ok := block["header"].(map[string]interface{})
+	if !ok {
+		return BlockDetails{}, fmt.Errorf("error extracting header from /block response")
+	}
+
+	proposer, ok := header["proposer_address"].(string)
 	if !ok {
 		return BlockDetails{}, fmt.Errorf("error extracting proposer_address from /block response")
 	}
+	// Some RPC versions encode the address as base64; it is always stored as upper case hex
+	if normalized, err := NormalizeAddress(proposer); err == nil {
+		proposer = normalized
+	}
+
+	blockTimeStr, ok := header["time"].(string)
+	if !ok {
+		return BlockDetails{}, fmt.Errorf("error extracting time from /block response")
+	}
+	blockTime, err := time.Parse(time.RFC3339Nano, blockTimeStr)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error parsing block time: %w", err)
+	}
+
+	// The genesis block has an empty app hash
+	appHash, _ := header["app_hash"].(string)
+
+	txs, err := decodeTxs(block)
+	if err != nil {
+		return BlockDetails{}, fmt.Errorf("error extracting txs from /block response: %w", err)
+	}
+	var blockSize int64
+	for _, tx := range txs {
+		blockSize += int64(len(tx))
+	}
 
 	blockDetails := BlockDetails{
-		BlockID:  blockID,
-		Proposer: proposer,
+		BlockID:        blockID,
+		Proposer:       proposer,
+		BlockTime:      blockTime.UTC(),
+		BlockSizeBytes: blockSize,
+		AppHash:        appHash,
+		TxMessageTypes: countMessageTypes(txs),
 	}
 	return blockDetails, nil
 }
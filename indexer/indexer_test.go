@@ -0,0 +1,241 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+// testRPCBlock is a block served by the fake RPC nodes of the tests
+var testRPCBlock = testrpc.Block{
+	Height:   5,
+	Hash:     "2E5B3C1A0F9D8E7C6B5A49382716F5E4D3C2B1A09F8E7D6C5B4A392817E6F5D4",
+	AppHash:  "9A8B7C6D5E4F30211203F4E5D6C7B8A99A8B7C6D5E4F30211203F4E5D6C7B8A9",
+	Proposer: "6B2FB9E8D4A9F1C2A5C7E3D1B0F4A6C8E2D9B7A1",
+	Time:     time.Date(2024, 3, 1, 12, 0, 0, 123456000, time.UTC),
+	GasUsed:  []int64{50000, 70000},
+}
+
+// newRPCIndexer returns an indexer without a database fetching blocks from the RPC node at url
+func newRPCIndexer(t *testing.T, url string) *Indexer {
+	t.Helper()
+	t.Setenv("RPC_URL", url)
+	return NewIndexer(nil, nil)
+}
+
+func TestGetBlockResultsFetchesBlockAndResults(t *testing.T) {
+	node := testrpc.NewNode(t)
+	node.AddBlock(testRPCBlock)
+	idx := newRPCIndexer(t, node.URL)
+
+	block, err := idx.getBlockResults(context.Background(), testRPCBlock.Height)
+	if err != nil {
+		t.Fatalf("getBlockResults: %v", err)
+	}
+
+	for _, path := range []string{"/block?height=5", "/block_results?height=5"} {
+		if n := node.Requests(path); n != 1 {
+			t.Errorf("%s requested %d times, want 1", path, n)
+		}
+	}
+	// block_id, proposer, time and app hash come from /block, the tx counts and gas from /block_results
+	if block.BlockID != testRPCBlock.Hash || block.Proposer != testRPCBlock.Proposer || !block.BlockTime.Equal(testRPCBlock.Time) {
+		t.Errorf("block fields = %s, %s, %v; want those of /block", block.BlockID, block.Proposer, block.BlockTime)
+	}
+	if block.AppHash == "" || block.AppHash != testRPCBlock.AppHash {
+		t.Errorf("app_hash = %q, want %q from /block", block.AppHash, testRPCBlock.AppHash)
+	}
+	if block.NumTransactions != 2 || block.TotalGasUsed != 120000 || block.TotalGasWanted != 240000 || block.NumEvents != 1 {
+		t.Errorf("results fields = %d txs, %d/%d gas, %d events; want those of /block_results",
+			block.NumTransactions, block.TotalGasUsed, block.TotalGasWanted, block.NumEvents)
+	}
+}
+
+func TestGetBlockResultsFetchesConcurrently(t *testing.T) {
+	// Each endpoint only answers once the other one is requested too, so serial fetches fail
+	var (
+		arrived sync.WaitGroup
+		both    = make(chan struct{})
+	)
+	arrived.Add(2)
+	go func() {
+		arrived.Wait()
+		close(both)
+	}()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived.Done()
+		select {
+		case <-both:
+		case <-time.After(5 * time.Second):
+			http.Error(w, "the other endpoint was not requested concurrently", http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Path == "/block" {
+			fmt.Fprint(w, testrpc.BlockResponse(testRPCBlock))
+			return
+		}
+		fmt.Fprint(w, testrpc.BlockResultsResponse(testRPCBlock))
+	}))
+	defer server.Close()
+	idx := newRPCIndexer(t, server.URL)
+
+	if _, err := idx.getBlockResults(context.Background(), testRPCBlock.Height); err != nil {
+		t.Fatalf("getBlockResults: %v", err)
+	}
+}
+
+func TestGetBlockResultsPropagatesErrors(t *testing.T) {
+	pruned := `{"jsonrpc":"2.0","id":-1,"error":{"code":-32603,"message":"Internal error","data":"height 5 is not available, lowest height is 100"}}`
+
+	for _, tc := range []struct {
+		name   string
+		failed string
+		status int
+		body   string
+		want   error
+	}{
+		{"block rate limited", "/block?height=5", http.StatusTooManyRequests, "", ErrRateLimited},
+		{"results rate limited", "/block_results?height=5", http.StatusTooManyRequests, "", ErrRateLimited},
+		{"block pruned", "/block?height=5", http.StatusOK, pruned, ErrBlockPruned},
+		{"results pruned", "/block_results?height=5", http.StatusOK, pruned, ErrBlockPruned},
+		{"results malformed", "/block_results?height=5", http.StatusOK, `{"jsonrpc":"2.0","result":`, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			node := testrpc.NewNode(t)
+			node.AddBlock(testRPCBlock)
+			node.HandleStatus(tc.failed, tc.status, tc.body)
+			idx := newRPCIndexer(t, node.URL)
+
+			_, err := idx.getBlockResults(context.Background(), testRPCBlock.Height)
+			if err == nil {
+				t.Fatal("getBlockResults succeeded")
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Errorf("error = %v, want it to wrap %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetLatestBlockHeightREST(t *testing.T) {
+	captured, err := os.ReadFile(filepath.Join("testdata", "rest_blocks_latest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		status int
+		body   string
+		want   int64
+	}{
+		{"captured", http.StatusOK, string(captured), 14480127},
+		{"truncated", http.StatusOK, string(captured[:len(captured)/2]), 0},
+		{"not JSON", http.StatusOK, "<html>502 Bad Gateway</html>", 0},
+		{"no header", http.StatusOK, `{"block_id":{},"block":{"data":{"txs":[]}}}`, 0},
+		{"no height", http.StatusOK, `{"block":{"header":{"chain_id":"omniflixhub-1"}}}`, 0},
+		{"numeric height", http.StatusOK, `{"block":{"header":{"height":14480127}}}`, 0},
+		{"invalid height", http.StatusOK, `{"block":{"header":{"height":"14480127a"}}}`, 0},
+		{"server error", http.StatusInternalServerError, `{"code":13,"message":"internal"}`, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/cosmos/base/tendermint/v1beta1/blocks/latest" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer server.Close()
+			t.Setenv("REST_URL", server.URL)
+			idx := newRPCIndexer(t, server.URL)
+
+			height, err := idx.getLatestBlockHeightREST()
+			if tc.want == 0 {
+				if err == nil {
+					t.Fatalf("getLatestBlockHeightREST = %d, want an error", height)
+				}
+				if idx.ChainHeight() != 0 {
+					t.Errorf("chain height %d observed from an invalid response", idx.ChainHeight())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getLatestBlockHeightREST: %v", err)
+			}
+			if height != tc.want || idx.ChainHeight() != tc.want {
+				t.Errorf("height = %d, chain height = %d; want %d", height, idx.ChainHeight(), tc.want)
+			}
+		})
+	}
+}
+
+func TestLargeIntegersKeepPrecision(t *testing.T) {
+	// 2^53 + 1 is the first integer a float64 cannot represent
+	const large = 9007199254740993
+
+	t.Run("block results", func(t *testing.T) {
+		node := testrpc.NewNode(t)
+		node.Handle("/block?height=5", testrpc.BlockResponse(testRPCBlock))
+		node.Handle("/block_results?height=5", fmt.Sprintf(`{"jsonrpc":"2.0","id":-1,"result":{
+			"height":"5",
+			"txs_results":[{"code":0,"gas_used":%d,"gas_wanted":"%d","events":[]}],
+			"finalize_block_events":[],
+			"consensus_param_updates":{"block":{"max_gas":%d}}}}`, large, large, large))
+		idx := newRPCIndexer(t, node.URL)
+
+		block, err := idx.getBlockResults(context.Background(), 5)
+		if err != nil {
+			t.Fatalf("getBlockResults: %v", err)
+		}
+		if block.TotalGasUsed != large || block.TotalGasWanted != large {
+			t.Errorf("gas = %d/%d, want %d for both the numeric and the string encoding", block.TotalGasUsed, block.TotalGasWanted, int64(large))
+		}
+		if !strings.Contains(string(block.Details), fmt.Sprintf(`"max_gas":%d`, large)) {
+			t.Errorf("details lost precision: %s", block.Details)
+		}
+	})
+
+	t.Run("status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":-1,"result":{"sync_info":{"latest_block_height":"%d","catching_up":false}}}`, large)
+		}))
+		defer server.Close()
+
+		height, err := getLatestBlockHeightFrom(server.Client(), server.URL)
+		if err != nil {
+			t.Fatalf("getLatestBlockHeightFrom: %v", err)
+		}
+		if height != large {
+			t.Errorf("height = %d, want %d", height, int64(large))
+		}
+	})
+
+	t.Run("REST", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"block":{"header":{"chain_id":"omniflixhub-1","height":"%d"}}}`, large)
+		}))
+		defer server.Close()
+		t.Setenv("REST_URL", server.URL)
+		idx := newRPCIndexer(t, server.URL)
+
+		height, err := idx.getLatestBlockHeightREST()
+		if err != nil {
+			t.Fatalf("getLatestBlockHeightREST: %v", err)
+		}
+		if height != large {
+			t.Errorf("height = %d, want %d", height, int64(large))
+		}
+	})
+}
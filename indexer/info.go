@@ -0,0 +1,56 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ChainID returns the chain id from CHAIN_ID, or the network reported by the RPC /status endpoint.
+// A successfully fetched value is cached for subsequent calls.
+func (idx *Indexer) ChainID() (string, error) {
+	idx.chainIDMu.Lock()
+	defer idx.chainIDMu.Unlock()
+
+	if idx.chainID != "" {
+		return idx.chainID, nil
+	}
+
+	var chainID string
+	err := idx.rpc.do(func(baseURL string) error {
+		var err error
+		chainID, err = getChainIDFrom(idx.client, baseURL)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	idx.chainID = chainID
+	return idx.chainID, nil
+}
+
+// getChainIDFrom fetches the network reported by the /status endpoint of the RPC node at baseURL
+func getChainIDFrom(client *http.Client, baseURL string) (string, error) {
+	resp, err := client.Get(baseURL + "/status")
+	if err != nil {
+		return "", fmt.Errorf("error fetching status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Result struct {
+			NodeInfo struct {
+				Network string `json:"network"`
+			} `json:"node_info"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("error decoding status: %w", err)
+	}
+	if result.Result.NodeInfo.Network == "" {
+		return "", fmt.Errorf("node_info.network not found in response")
+	}
+
+	return result.Result.NodeInfo.Network, nil
+}
@@ -0,0 +1,88 @@
+package indexer
+
+import (
+	"sync"
+	"time"
+)
+
+// latestCache caches the highest indexed blocks for GetLatestBlocks, so dashboards polling the
+// tip don't query the database on every request
+type latestCache struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	blocks []BlockDetails
+	// complete is set when fewer blocks than requested were indexed, so blocks holds them all
+	complete  bool
+	fetchedAt time.Time
+	// generation is bumped by invalidate, so a query racing with a store does not cache stale blocks
+	generation uint64
+}
+
+// get returns the n highest cached blocks, or false when the cache is stale or holds fewer blocks
+func (c *latestCache) get(n int) ([]BlockDetails, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.blocks == nil || time.Since(c.fetchedAt) >= c.ttl || (len(c.blocks) < n && !c.complete) {
+		return nil, c.generation, false
+	}
+	if n > len(c.blocks) {
+		n = len(c.blocks)
+	}
+	return append([]BlockDetails(nil), c.blocks[:n]...), c.generation, true
+}
+
+// put caches the n highest blocks read at generation, unless the cache was invalidated meanwhile
+func (c *latestCache) put(blocks []BlockDetails, n int, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != generation {
+		return
+	}
+	c.blocks = blocks
+	c.complete = len(blocks) < n
+	c.fetchedAt = time.Now()
+}
+
+// invalidate drops the cached blocks when height is one of them or above them, or when they are all
+// the indexed blocks, as any new block is then among the highest
+func (c *latestCache) invalidate(height int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.blocks) > 0 && !c.complete && height < c.blocks[len(c.blocks)-1].Height {
+		return
+	}
+	c.blocks = nil
+	c.generation++
+}
+
+// reset drops the cached blocks whatever their height
+func (c *latestCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.blocks = nil
+	c.generation++
+}
+
+// GetLatestBlocks returns the n highest indexed blocks, highest first. Results are cached for
+// LATEST_BLOCKS_CACHE_TTL; storing a block at or above the cached ones invalidates the cache.
+func (idx *Indexer) GetLatestBlocks(n int) ([]BlockDetails, error) {
+	if idx.latest.ttl <= 0 {
+		return idx.ListBlocks(BlockFilter{}, SortHeightDesc, n, 0, nil)
+	}
+	blocks, generation, ok := idx.latest.get(n)
+	if ok {
+		return blocks, nil
+	}
+
+	blocks, err := idx.ListBlocks(BlockFilter{}, SortHeightDesc, n, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	idx.latest.put(append([]BlockDetails(nil), blocks...), n, generation)
+	return blocks, nil
+}
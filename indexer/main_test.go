@@ -0,0 +1,75 @@
+//go:build integration
+
+package indexer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/muhammadfarhankt/omniFlix/db"
+	"github.com/muhammadfarhankt/omniFlix/internal/testdb"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Main(m))
+}
+
+// newTestIndexer returns an indexer on a migrated, empty database
+func newTestIndexer(t *testing.T) *Indexer {
+	t.Helper()
+	sqlDB := testdb.Open(t)
+	if err := (&db.DB{DB: sqlDB, Read: sqlDB}).CreateTable(); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	return NewIndexer(sqlDB, nil)
+}
+
+// insertBlock stores a block row as the indexer would, with NULL for its empty block_id, proposer, block time and details
+func insertBlock(t *testing.T, idx *Indexer, block BlockDetails) {
+	t.Helper()
+	messageTypes, err := json.Marshal(block.TxMessageTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var details interface{}
+	if block.Details != nil {
+		details = string(block.Details)
+	}
+	_, err = idx.db.Exec(`
+		INSERT INTO blocks (block_height, block_id, proposer_address, block_time, num_transactions, num_events, total_gas_used, total_gas_wanted, block_size_bytes, app_hash, tx_message_types, details, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())`,
+		block.Height, nullString(block.BlockID), nullString(block.Proposer), nullTime(block.BlockTime), block.NumTransactions, block.NumEvents,
+		block.TotalGasUsed, block.TotalGasWanted, block.BlockSizeBytes, block.AppHash, messageTypes, details)
+	if err != nil {
+		t.Fatalf("error inserting block %d: %v", block.Height, err)
+	}
+}
+
+// nullString converts an empty string to a SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// indexedHeights returns the heights of the blocks table in ascending order
+func indexedHeights(t *testing.T, idx *Indexer) []int64 {
+	t.Helper()
+	rows, err := idx.db.Query("SELECT block_height FROM blocks ORDER BY block_height")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var heights []int64
+	for rows.Next() {
+		var height int64
+		if err := rows.Scan(&height); err != nil {
+			t.Fatal(err)
+		}
+		heights = append(heights, height)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return heights
+}
@@ -0,0 +1,29 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Maintenance is the outcome of a MaintainBlocks run
+type Maintenance struct {
+	Vacuum     bool  `json:"vacuum"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// MaintainBlocks refreshes the planner statistics of the blocks table, reclaiming dead rows first when
+// vacuum is set. The statements run on the primary, which DB_STATEMENT_TIMEOUT does not apply to, since
+// they scan the whole table.
+func (idx *Indexer) MaintainBlocks(ctx context.Context, vacuum bool) (*Maintenance, error) {
+	start := time.Now()
+	statement := "ANALYZE blocks"
+	if vacuum {
+		statement = "VACUUM (ANALYZE) blocks"
+	}
+	if _, err := idx.db.ExecContext(ctx, statement); err != nil {
+		return nil, fmt.Errorf("error running %s: %w", statement, err)
+	}
+
+	return &Maintenance{Vacuum: vacuum, DurationMS: time.Since(start).Milliseconds()}, nil
+}
@@ -0,0 +1,23 @@
+package indexer
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	fetchDeduplicated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "fetch_deduplicated_total",
+		Help: "Number of block fetches that shared an in-flight fetch of the same height.",
+	})
+
+	fetchInflight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "fetch_inflight",
+		Help: "Number of block fetches currently in flight, after deduplication.",
+	})
+
+	latestHeightRegressions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "latest_height_regressions_total",
+		Help: "Number of times the reported latest block height was lower than a previously reported one.",
+	})
+)
@@ -0,0 +1,116 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// NewProposerEvent is the payload of the NEW_PROPOSER_WEBHOOK_URL webhook
+type NewProposerEvent struct {
+	Event     string     `json:"event"`
+	Proposer  string     `json:"proposer"`
+	Height    int64      `json:"height"`
+	BlockTime *time.Time `json:"block_time,omitempty"`
+}
+
+// proposerMonitor tracks the set of known proposers, persisted in known_proposers, to alert on new validators
+type proposerMonitor struct {
+	url string
+
+	mu    sync.Mutex
+	known map[string]bool
+	// notifyFrom is the lowest height whose new proposers are notified; backfilled blocks are recorded silently
+	notifyFrom int64
+}
+
+// StartProposerMonitor seeds the known proposers from the indexed blocks and loads them, so that blocks
+// above tip proposed by an unknown address fire the NEW_PROPOSER_WEBHOOK_URL webhook. It is a no-op
+// without a webhook URL.
+func (idx *Indexer) StartProposerMonitor(tip int64) error {
+	m := &idx.proposerMonitor
+	if m.url == "" {
+		return nil
+	}
+	if tip <= 0 {
+		return fmt.Errorf("unknown chain tip, new proposers are not monitored")
+	}
+
+	_, err := idx.db.Exec(`
+		INSERT INTO known_proposers (address, first_height, first_seen_at)
+		SELECT proposer_address, MIN(block_height), NOW()
+		FROM blocks
+		WHERE proposer_address IS NOT NULL AND proposer_address <> ''
+		GROUP BY proposer_address
+		ON CONFLICT (address) DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("error seeding known proposers: %w", err)
+	}
+
+	rows, err := idx.db.Query("SELECT address FROM known_proposers")
+	if err != nil {
+		return fmt.Errorf("error fetching known proposers: %w", err)
+	}
+	defer rows.Close()
+
+	known := map[string]bool{}
+	for rows.Next() {
+		var address string
+		if err := rows.Scan(&address); err != nil {
+			return fmt.Errorf("error scanning known proposer: %w", err)
+		}
+		known[address] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating known proposers: %w", err)
+	}
+
+	m.mu.Lock()
+	m.known = known
+	m.notifyFrom = tip + 1
+	m.mu.Unlock()
+
+	log.Printf("Monitoring new proposers above height %d (%d known)", tip, len(known))
+	return nil
+}
+
+// checkProposer records the proposer of a stored block, firing the webhook when it is new at the tip
+func (idx *Indexer) checkProposer(ctx context.Context, blockDetails BlockDetails) {
+	m := &idx.proposerMonitor
+	address := blockDetails.Proposer
+
+	m.mu.Lock()
+	if m.known == nil || address == "" || m.known[address] {
+		m.mu.Unlock()
+		return
+	}
+	m.known[address] = true
+	notify := blockDetails.Height >= m.notifyFrom
+	m.mu.Unlock()
+
+	result, err := idx.db.ExecContext(ctx, `
+		INSERT INTO known_proposers (address, first_height, first_seen_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (address) DO NOTHING`, address, blockDetails.Height)
+	if err != nil {
+		log.Printf("Error recording proposer %s: %v", address, err)
+		// Forget it so the next block of the proposer retries
+		m.mu.Lock()
+		delete(m.known, address)
+		m.mu.Unlock()
+		return
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 || !notify {
+		return
+	}
+
+	log.Printf("New proposer %s at height %d", address, blockDetails.Height)
+	event := NewProposerEvent{Event: "new_proposer", Proposer: address, Height: blockDetails.Height}
+	if !blockDetails.BlockTime.IsZero() {
+		t := blockDetails.BlockTime.UTC()
+		event.BlockTime = &t
+	}
+	go idx.postWebhook(m.url, event)
+}
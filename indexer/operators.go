@@ -0,0 +1,51 @@
+package indexer
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrValidatorNotFound is returned when an operator address is not a validator of the chain
+var ErrValidatorNotFound = errors.New("validator not found")
+
+// operatorResolver caches the consensus address of every validator by operator (valoper) address
+type operatorResolver struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	consensus map[string]string
+	fetchedAt time.Time
+}
+
+// ConsensusAddress returns the hex consensus address, as stored in proposer_address, of the validator
+// with the operator address valoper. The mapping of every validator, bonded or not, is fetched from the
+// staking REST API and cached for VALIDATOR_CACHE_TTL; an unknown address refreshes an older mapping once,
+// so validators created since the last fetch are found.
+func (idx *Indexer) ConsensusAddress(valoper string) (string, error) {
+	r := &idx.operators
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if address, ok := r.consensus[valoper]; ok && time.Since(r.fetchedAt) < r.ttl {
+		return address, nil
+	}
+	// Bound refreshes for unknown addresses to one per minute
+	if r.consensus == nil || time.Since(r.fetchedAt) >= r.ttl || time.Since(r.fetchedAt) >= time.Minute {
+		validators, err := idx.fetchValidators("")
+		if err != nil {
+			return "", err
+		}
+		r.consensus = make(map[string]string, len(validators))
+		for _, v := range validators {
+			r.consensus[v.OperatorAddress] = v.ConsensusAddress
+		}
+		r.fetchedAt = time.Now()
+	}
+
+	address, ok := r.consensus[valoper]
+	if !ok {
+		return "", ErrValidatorNotFound
+	}
+	return address, nil
+}
@@ -0,0 +1,106 @@
+package indexer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Moving average settings of the indexing rate
+const (
+	rateSampleInterval = 5 * time.Second
+	rateSmoothing      = 0.3
+)
+
+// rateTracker estimates the number of blocks stored per second as an exponential moving average
+type rateTracker struct {
+	mu         sync.Mutex
+	count      int64
+	sampleFrom time.Time
+	rate       float64
+}
+
+// record counts a stored block, folding the pending count into the average once per sample interval
+func (r *rateTracker) record() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if r.sampleFrom.IsZero() {
+		r.sampleFrom = now
+	}
+	r.count++
+
+	if elapsed := now.Sub(r.sampleFrom); elapsed >= rateSampleInterval {
+		r.rate = rateSmoothing*(float64(r.count)/elapsed.Seconds()) + (1-rateSmoothing)*r.rate
+		r.count = 0
+		r.sampleFrom = now
+	}
+}
+
+// reset clears the recorded rate
+func (r *rateTracker) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.count = 0
+	r.sampleFrom = time.Time{}
+	r.rate = 0
+}
+
+// perSecond returns the average rate, decayed by the time elapsed since the last sample so a stalled indexer trends to zero
+func (r *rateTracker) perSecond() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sampleFrom.IsZero() {
+		return 0
+	}
+	if elapsed := time.Since(r.sampleFrom); elapsed >= rateSampleInterval {
+		return rateSmoothing*(float64(r.count)/elapsed.Seconds()) + (1-rateSmoothing)*r.rate
+	}
+	return r.rate
+}
+
+// Progress represents how much of the configured height range is indexed
+type Progress struct {
+	StartHeight  int64   `json:"start_height"`
+	ChainHeight  int64   `json:"chain_height"`
+	Indexed      int64   `json:"indexed"`
+	Total        int64   `json:"total"`
+	Percent      float64 `json:"percent"`
+	BlocksPerSec float64 `json:"blocks_per_second"`
+	ETASeconds   *int64  `json:"eta_seconds"`
+	// BackfillFrontier is the highest height the backfill still has to index;
+	// the backfill is complete once it is below StartHeight
+	BackfillFrontier int64 `json:"backfill_frontier,omitempty"`
+}
+
+// GetProgress returns the share of heights indexed between the indexing start height and the chain height,
+// with an ETA based on the moving average indexing rate (null while the rate is unknown)
+func (idx *Indexer) GetProgress() (*Progress, error) {
+	progress := Progress{
+		StartHeight:      idx.startHeight.Load(),
+		ChainHeight:      idx.ChainHeight(),
+		BackfillFrontier: idx.backfillFrontier.Load(),
+		BlocksPerSec:     idx.rate.perSecond(),
+	}
+	if progress.StartHeight == 0 || progress.ChainHeight < progress.StartHeight {
+		return nil, fmt.Errorf("indexing has not started yet")
+	}
+
+	indexed, err := idx.CountBlocks(BlockFilter{From: progress.StartHeight, To: progress.ChainHeight})
+	if err != nil {
+		return nil, err
+	}
+	progress.Indexed = indexed
+	progress.Total = progress.ChainHeight - progress.StartHeight + 1
+	progress.Percent = float64(indexed) / float64(progress.Total) * 100
+
+	if progress.BlocksPerSec > 0 {
+		eta := int64(float64(progress.Total-progress.Indexed) / progress.BlocksPerSec)
+		progress.ETASeconds = &eta
+	}
+
+	return &progress, nil
+}
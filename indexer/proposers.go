@@ -0,0 +1,328 @@
+package indexer
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ProposedBlock represents a block in a proposer's timeline
+type ProposedBlock struct {
+	Height    int64      `json:"height"`
+	BlockTime *time.Time `json:"block_time,omitempty"`
+}
+
+// GetProposerTimeline returns the heights proposed by address between from and to (inclusive), in ascending order
+func (idx *Indexer) GetProposerTimeline(address string, from, to int64) ([]ProposedBlock, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT block_height, block_time
+		FROM blocks
+		WHERE proposer_address = $1 AND block_height BETWEEN $2 AND $3
+		ORDER BY block_height`, address, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching proposer timeline: %w", err)
+	}
+	defer rows.Close()
+
+	timeline := []ProposedBlock{}
+	for rows.Next() {
+		var (
+			block     ProposedBlock
+			blockTime sql.NullTime
+		)
+		if err := rows.Scan(&block.Height, &blockTime); err != nil {
+			return nil, fmt.Errorf("error scanning proposer timeline: %w", err)
+		}
+		if blockTime.Valid {
+			t := blockTime.Time.UTC()
+			block.BlockTime = &t
+		}
+		timeline = append(timeline, block)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proposer timeline: %w", err)
+	}
+
+	return timeline, nil
+}
+
+// GetProposerRecent returns the blocks proposed by address with a block time at or after since, latest first
+func (idx *Indexer) GetProposerRecent(address string, since time.Time) ([]ProposedBlock, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT block_height, block_time
+		FROM blocks
+		WHERE proposer_address = $1 AND block_time >= $2
+		ORDER BY block_time DESC, block_height DESC`, address, since)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching recent proposer blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []ProposedBlock{}
+	for rows.Next() {
+		var (
+			block     ProposedBlock
+			blockTime time.Time
+		)
+		if err := rows.Scan(&block.Height, &blockTime); err != nil {
+			return nil, fmt.Errorf("error scanning recent proposer block: %w", err)
+		}
+		blockTime = blockTime.UTC()
+		block.BlockTime = &blockTime
+		blocks = append(blocks, block)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recent proposer blocks: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// ProposerTurn represents the proposer of a height in the proposer rotation
+type ProposerTurn struct {
+	Height   int64  `json:"height"`
+	Proposer string `json:"proposer"`
+}
+
+// GetProposerSequence returns the proposer of every indexed height between from and to (inclusive), in ascending order.
+// Heights stored without a proposer are left out.
+func (idx *Indexer) GetProposerSequence(from, to int64) ([]ProposerTurn, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT block_height, proposer_address
+		FROM blocks
+		WHERE block_height BETWEEN $1 AND $2 AND proposer_address IS NOT NULL AND proposer_address <> ''
+		ORDER BY block_height`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching proposer sequence: %w", err)
+	}
+	defer rows.Close()
+
+	sequence := []ProposerTurn{}
+	for rows.Next() {
+		var turn ProposerTurn
+		if err := rows.Scan(&turn.Height, &turn.Proposer); err != nil {
+			return nil, fmt.Errorf("error scanning proposer sequence: %w", err)
+		}
+		sequence = append(sequence, turn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proposer sequence: %w", err)
+	}
+
+	return sequence, nil
+}
+
+// CountBlocksByProposers returns the number of blocks proposed by any of addresses between from and to (inclusive)
+func (idx *Indexer) CountBlocksByProposers(addresses []string, from, to int64) (int64, error) {
+	var count int64
+	err := idx.readDB.QueryRow(`
+		SELECT COUNT(*)
+		FROM blocks
+		WHERE proposer_address = ANY($1) AND block_height BETWEEN $2 AND $3`, pq.Array(addresses), from, to).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting blocks by proposers: %w", err)
+	}
+	return count, nil
+}
+
+// GetBlocksByProposers returns the blocks proposed by any of addresses between from and to (inclusive), ordered by height,
+// skipping the large columns fields does not project
+func (idx *Indexer) GetBlocksByProposers(addresses []string, from, to int64, limit, offset int, fields []string) ([]BlockDetails, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT `+blockColumnsFor(fields)+`
+		FROM blocks
+		WHERE proposer_address = ANY($1) AND block_height BETWEEN $2 AND $3
+		ORDER BY block_height
+		LIMIT $4 OFFSET $5`, pq.Array(addresses), from, to, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching blocks by proposers: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []BlockDetails{}
+	for rows.Next() {
+		blockDetails, err := scanBlock(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning block: %w", err)
+		}
+		blocks = append(blocks, blockDetails)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blocks: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// ProposerLastSeen is the latest block proposed by a proposer
+type ProposerLastSeen struct {
+	Proposer  string     `json:"proposer"`
+	Height    int64      `json:"height"`
+	BlockTime *time.Time `json:"block_time,omitempty"`
+}
+
+// GetProposersLastSeen returns the highest indexed block of every proposer, ordered by proposer
+func (idx *Indexer) GetProposersLastSeen() ([]ProposerLastSeen, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT DISTINCT ON (proposer_address) proposer_address, block_height, block_time
+		FROM blocks
+		WHERE proposer_address IS NOT NULL AND proposer_address <> ''
+		ORDER BY proposer_address, block_height DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching proposers last seen: %w", err)
+	}
+	defer rows.Close()
+
+	lastSeen := []ProposerLastSeen{}
+	for rows.Next() {
+		var (
+			seen      ProposerLastSeen
+			blockTime sql.NullTime
+		)
+		if err := rows.Scan(&seen.Proposer, &seen.Height, &blockTime); err != nil {
+			return nil, fmt.Errorf("error scanning proposer last seen: %w", err)
+		}
+		if blockTime.Valid {
+			t := blockTime.Time.UTC()
+			seen.BlockTime = &t
+		}
+		lastSeen = append(lastSeen, seen)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proposers last seen: %w", err)
+	}
+
+	return lastSeen, nil
+}
+
+// ProposerStreak is the longest run of blocks a proposer did not propose between two of its proposals
+type ProposerStreak struct {
+	Proposer  string `json:"proposer"`
+	Proposals int64  `json:"proposals"`
+	// LongestAbsence is the number of blocks of the streak, 0 when the proposer proposed at most once
+	// or only in consecutive blocks
+	LongestAbsence int64 `json:"longest_absence"`
+	// AbsenceFrom and AbsenceTo are the first and last heights of the streak
+	AbsenceFrom *int64 `json:"absence_from,omitempty"`
+	AbsenceTo   *int64 `json:"absence_to,omitempty"`
+}
+
+// GetProposerStreaks returns the longest absence streak of every proposer of the height range (inclusive),
+// longest first. On ties the earliest streak of a proposer is reported.
+func (idx *Indexer) GetProposerStreaks(from, to int64) ([]ProposerStreak, error) {
+	rows, err := idx.readDB.Query(`
+		WITH proposals AS (
+			SELECT proposer_address, block_height,
+				LAG(block_height) OVER (PARTITION BY proposer_address ORDER BY block_height) AS prev
+			FROM blocks
+			WHERE block_height BETWEEN $1 AND $2 AND proposer_address IS NOT NULL AND proposer_address <> ''
+		), ranked AS (
+			SELECT proposer_address, block_height, prev,
+				COUNT(*) OVER (PARTITION BY proposer_address) AS proposals,
+				ROW_NUMBER() OVER (PARTITION BY proposer_address ORDER BY block_height - prev DESC NULLS LAST, block_height) AS rank
+			FROM proposals
+		)
+		SELECT proposer_address, proposals, COALESCE(block_height - prev - 1, 0) AS longest,
+			CASE WHEN block_height - prev > 1 THEN prev + 1 END,
+			CASE WHEN block_height - prev > 1 THEN block_height - 1 END
+		FROM ranked
+		WHERE rank = 1
+		ORDER BY longest DESC, proposer_address`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching proposer streaks: %w", err)
+	}
+	defer rows.Close()
+
+	streaks := []ProposerStreak{}
+	for rows.Next() {
+		var (
+			streak                 ProposerStreak
+			absenceFrom, absenceTo sql.NullInt64
+		)
+		if err := rows.Scan(&streak.Proposer, &streak.Proposals, &streak.LongestAbsence, &absenceFrom, &absenceTo); err != nil {
+			return nil, fmt.Errorf("error scanning proposer streak: %w", err)
+		}
+		if absenceFrom.Valid && absenceTo.Valid {
+			streak.AbsenceFrom, streak.AbsenceTo = &absenceFrom.Int64, &absenceTo.Int64
+		}
+		streaks = append(streaks, streak)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proposer streaks: %w", err)
+	}
+
+	return streaks, nil
+}
+
+// GetActiveProposers returns every proposer of at least one indexed block between from and to (inclusive)
+// with its block count, ordered by address
+func (idx *Indexer) GetActiveProposers(from, to int64) ([]ProposerStats, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT proposer_address, COUNT(*)
+		FROM blocks
+		WHERE block_height BETWEEN $1 AND $2 AND proposer_address IS NOT NULL AND proposer_address <> ''
+		GROUP BY proposer_address
+		ORDER BY proposer_address`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching active proposers: %w", err)
+	}
+	defer rows.Close()
+
+	proposers := []ProposerStats{}
+	for rows.Next() {
+		var proposer ProposerStats
+		if err := rows.Scan(&proposer.Proposer, &proposer.Blocks); err != nil {
+			return nil, fmt.Errorf("error scanning active proposer: %w", err)
+		}
+		proposers = append(proposers, proposer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating active proposers: %w", err)
+	}
+
+	return proposers, nil
+}
+
+// ProposerRun is the run of consecutive indexed blocks sharing the proposer of a height
+type ProposerRun struct {
+	Height   int64  `json:"height"`
+	Proposer string `json:"proposer"`
+	// From and To are the first and last heights of the run, which includes Height
+	From   int64 `json:"from"`
+	To     int64 `json:"to"`
+	Length int64 `json:"length"`
+	// Truncated is set when the run reaches the cap on either side of Height and may extend further
+	Truncated bool `json:"truncated"`
+}
+
+// GetProposerRun returns the run of contiguous indexed blocks around height proposed by the proposer of
+// height, walking at most limit blocks backward and forward. A missing height ends the run like a block
+// of another proposer. It returns ErrBlockNotFound when height is not indexed.
+func (idx *Indexer) GetProposerRun(height int64, limit int) (*ProposerRun, error) {
+	from, to := height-int64(limit), height+int64(limit)
+	// Heights of a run minus their rank among the blocks of the proposer are constant, and differ between runs
+	run := &ProposerRun{Height: height}
+	err := idx.readDB.QueryRow(`
+		WITH window_blocks AS (
+			SELECT block_height, proposer_address,
+				block_height - ROW_NUMBER() OVER (PARTITION BY proposer_address ORDER BY block_height) AS run
+			FROM blocks
+			WHERE block_height BETWEEN $2 AND $3
+		)
+		SELECT COALESCE(w.proposer_address, ''), MIN(w.block_height), MAX(w.block_height), COUNT(*)
+		FROM window_blocks w
+		JOIN window_blocks target ON target.block_height = $1
+		WHERE w.proposer_address IS NOT DISTINCT FROM target.proposer_address AND w.run = target.run
+		GROUP BY w.proposer_address`, height, from, to).Scan(&run.Proposer, &run.From, &run.To, &run.Length)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: block %d is not indexed", ErrBlockNotFound, height)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching proposer run: %w", err)
+	}
+	run.Truncated = run.From == from || run.To == to
+
+	return run, nil
+}
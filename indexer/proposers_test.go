@@ -0,0 +1,91 @@
+//go:build integration
+
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetProposerRun(t *testing.T) {
+	idx := newTestIndexer(t)
+
+	proposer := func(c string) string { return strings.Repeat(c, 40) }
+	// A proposes 1-3, B 4-12 and C 13-20, except for the missing height 15
+	for height := int64(1); height <= 20; height++ {
+		p := "C"
+		switch {
+		case height == 15:
+			continue
+		case height <= 3:
+			p = "A"
+		case height <= 12:
+			p = "B"
+		}
+		insertBlock(t, idx, BlockDetails{Height: height, BlockID: fmt.Sprintf("%064X", height), Proposer: proposer(p)})
+	}
+
+	for _, tc := range []struct {
+		height    int64
+		limit     int
+		want      ProposerRun
+		truncated bool
+	}{
+		// The window [height-limit, height+limit] covers the whole run
+		{8, 10, ProposerRun{Height: 8, Proposer: proposer("B"), From: 4, To: 12, Length: 9}, false},
+		// The run ends exactly at the window bounds, so it may extend beyond them
+		{8, 4, ProposerRun{Height: 8, Proposer: proposer("B"), From: 4, To: 12, Length: 9}, true},
+		{4, 8, ProposerRun{Height: 4, Proposer: proposer("B"), From: 4, To: 12, Length: 9}, true},
+		// The window cuts the run on both sides
+		{8, 3, ProposerRun{Height: 8, Proposer: proposer("B"), From: 5, To: 11, Length: 7}, true},
+		// The window cuts the run on one side only
+		{10, 3, ProposerRun{Height: 10, Proposer: proposer("B"), From: 7, To: 12, Length: 6}, true},
+		// The first indexed block starts a run
+		{2, 5, ProposerRun{Height: 2, Proposer: proposer("A"), From: 1, To: 3, Length: 3}, false},
+		// A missing height ends the run like a block of another proposer
+		{17, 10, ProposerRun{Height: 17, Proposer: proposer("C"), From: 16, To: 20, Length: 5}, false},
+		{14, 10, ProposerRun{Height: 14, Proposer: proposer("C"), From: 13, To: 14, Length: 2}, false},
+		{14, 1, ProposerRun{Height: 14, Proposer: proposer("C"), From: 13, To: 14, Length: 2}, true},
+	} {
+		t.Run(fmt.Sprintf("height %d limit %d", tc.height, tc.limit), func(t *testing.T) {
+			run, err := idx.GetProposerRun(tc.height, tc.limit)
+			if err != nil {
+				t.Fatalf("GetProposerRun: %v", err)
+			}
+			tc.want.Truncated = tc.truncated
+			if *run != tc.want {
+				t.Errorf("GetProposerRun = %+v, want %+v", *run, tc.want)
+			}
+		})
+	}
+
+	for _, height := range []int64{15, 21, 1000} {
+		if _, err := idx.GetProposerRun(height, 10); !errors.Is(err, ErrBlockNotFound) {
+			t.Errorf("GetProposerRun(%d) error = %v, want ErrBlockNotFound", height, err)
+		}
+	}
+}
+
+func TestGetProposerSequenceSkipsMissingProposers(t *testing.T) {
+	idx := newTestIndexer(t)
+	proposer := strings.Repeat("A", 40)
+	insertBlock(t, idx, BlockDetails{Height: 1, Proposer: proposer})
+	// Stored with a NULL, then an empty proposer
+	insertBlock(t, idx, BlockDetails{Height: 2})
+	insertBlock(t, idx, BlockDetails{Height: 3})
+	if _, err := idx.db.Exec(`UPDATE blocks SET proposer_address = '' WHERE block_height = 3`); err != nil {
+		t.Fatal(err)
+	}
+	insertBlock(t, idx, BlockDetails{Height: 4, Proposer: proposer})
+
+	sequence, err := idx.GetProposerSequence(1, 4)
+	if err != nil {
+		t.Fatalf("GetProposerSequence: %v", err)
+	}
+	if want := []ProposerTurn{{1, proposer}, {4, proposer}}; !reflect.DeepEqual(sequence, want) {
+		t.Errorf("GetProposerSequence = %v, want %v", sequence, want)
+	}
+}
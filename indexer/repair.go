@@ -0,0 +1,141 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/config"
+)
+
+// stateRepairCursor is the indexer_state key of the highest height verified by the repair job
+const stateRepairCursor = "repair_cursor"
+
+// RunRepair periodically re-indexes suspicious blocks, every REPAIR_INTERVAL (disabled when 0), until ctx
+// is cancelled. Each run re-indexes up to REPAIR_BATCH_SIZE blocks with a missing block_id or proposer,
+// then verifies the next REPAIR_BATCH_SIZE heights against a fresh fetch, resuming from a cursor stored
+// in indexer_state and wrapping around at the tip. Repairs are recorded in the block_repairs table.
+func (idx *Indexer) RunRepair(ctx context.Context) {
+	interval := config.Duration("REPAIR_INTERVAL", 0)
+	batchSize := config.Int("REPAIR_BATCH_SIZE", 100)
+	if interval <= 0 || batchSize <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := idx.repairIncomplete(ctx, batchSize); err != nil {
+			log.Printf("Error repairing incomplete blocks: %v", err)
+		}
+		if err := idx.repairMismatches(ctx, batchSize); err != nil {
+			log.Printf("Error verifying blocks for repair: %v", err)
+		}
+	}
+}
+
+// repairIncomplete re-indexes up to limit blocks stored without a block_id or proposer
+func (idx *Indexer) repairIncomplete(ctx context.Context, limit int) error {
+	heights, err := idx.queryHeights(ctx, `
+		SELECT block_height FROM blocks
+		WHERE (COALESCE(block_id, '') = '' OR COALESCE(proposer_address, '') = '') AND manually_edited IS NOT TRUE
+		ORDER BY block_height
+		LIMIT $1`, limit)
+	if err != nil {
+		return fmt.Errorf("error fetching incomplete blocks: %w", err)
+	}
+
+	for _, height := range heights {
+		if ctx.Err() != nil {
+			return nil
+		}
+		idx.repairBlock(ctx, height, "missing block_id or proposer")
+	}
+	return nil
+}
+
+// repairMismatches verifies the limit heights above the repair cursor against a fresh fetch and
+// re-indexes those that disagree, then advances the cursor
+func (idx *Indexer) repairMismatches(ctx context.Context, limit int) error {
+	cursor, _, err := idx.getState(stateRepairCursor)
+	if err != nil {
+		return err
+	}
+	heights, err := idx.queryHeights(ctx, `
+		SELECT block_height FROM blocks
+		WHERE block_height > $1 AND manually_edited IS NOT TRUE
+		ORDER BY block_height
+		LIMIT $2`, cursor, limit)
+	if err != nil {
+		return fmt.Errorf("error fetching blocks to verify: %w", err)
+	}
+	// Start over from the lowest block once the cursor reaches the tip
+	if len(heights) == 0 {
+		return idx.setState(stateRepairCursor, 0)
+	}
+
+	for _, height := range heights {
+		if ctx.Err() != nil {
+			return nil
+		}
+		verification, err := idx.VerifyBlock(height)
+		if err != nil {
+			// Transient failures are retried on the next pass over the chain
+			log.Printf("Error verifying block %d for repair: %v", height, err)
+			continue
+		}
+		if !verification.Matches {
+			fields := make([]string, 0, len(verification.Diff))
+			for field := range verification.Diff {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			idx.repairBlock(ctx, height, "mismatching "+strings.Join(fields, ", "))
+		}
+	}
+
+	return idx.setState(stateRepairCursor, heights[len(heights)-1])
+}
+
+// repairBlock re-indexes height and, once the block is stored, records the repair and its reason in block_repairs
+func (idx *Indexer) repairBlock(ctx context.Context, height int64, reason string) {
+	if _, err := idx.fetchAndStoreBlockDetailsNow(ctx, height, true); err != nil {
+		log.Printf("Error repairing block %d (%s): %v", height, reason, err)
+		return
+	}
+	log.Printf("Repaired block %d: %s", height, reason)
+
+	_, err := idx.db.ExecContext(ctx, `INSERT INTO block_repairs (block_height, reason, repaired_at) VALUES ($1, $2, $3)`,
+		height, reason, time.Now())
+	if err != nil {
+		log.Printf("Error recording repair of block %d: %v", height, err)
+	}
+}
+
+// queryHeights runs a query selecting block heights, with args
+func (idx *Indexer) queryHeights(ctx context.Context, query string, args ...interface{}) ([]int64, error) {
+	rows, err := idx.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var heights []int64
+	for rows.Next() {
+		var height int64
+		if err := rows.Scan(&height); err != nil {
+			return nil, err
+		}
+		heights = append(heights, height)
+	}
+	return heights, rows.Err()
+}
@@ -0,0 +1,61 @@
+//go:build integration
+
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+func TestRepairIncomplete(t *testing.T) {
+	node := testrpc.NewNode(t)
+	node.AddBlock(testRPCBlock)
+	t.Setenv("RPC_URL", node.URL)
+	idx := newTestIndexer(t)
+
+	// 5 and 6 are incomplete, but the node has no block 6; 7 is complete
+	insertBlock(t, idx, BlockDetails{Height: 5})
+	insertBlock(t, idx, BlockDetails{Height: 6, BlockID: fmt.Sprintf("%064X", 6)})
+	insertBlock(t, idx, BlockDetails{Height: 7, BlockID: fmt.Sprintf("%064X", 7), Proposer: testRPCBlock.Proposer})
+
+	if err := idx.repairIncomplete(context.Background(), 10); err != nil {
+		t.Fatalf("repairIncomplete: %v", err)
+	}
+
+	// The repair is stored by the time it is recorded
+	block, _, err := idx.GetBlockDetails(5)
+	if err != nil {
+		t.Fatalf("GetBlockDetails: %v", err)
+	}
+	if block.BlockID != testRPCBlock.Hash || block.Proposer != testRPCBlock.Proposer {
+		t.Errorf("repaired block = %s, %s; want %s, %s", block.BlockID, block.Proposer, testRPCBlock.Hash, testRPCBlock.Proposer)
+	}
+
+	rows, err := idx.db.Query("SELECT block_height, reason FROM block_repairs ORDER BY block_height")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var repairs []string
+	for rows.Next() {
+		var (
+			height int64
+			reason string
+		)
+		if err := rows.Scan(&height, &reason); err != nil {
+			t.Fatal(err)
+		}
+		repairs = append(repairs, fmt.Sprintf("%d: %s", height, reason))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	// The failed repair of 6 is not recorded
+	if want := []string{"5: missing block_id or proposer"}; !reflect.DeepEqual(repairs, want) {
+		t.Errorf("recorded repairs = %q, want %q", repairs, want)
+	}
+}
@@ -0,0 +1,55 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Retry settings for writes that fail on transient transaction conflicts
+const (
+	maxWriteAttempts = 4
+	writeRetryDelay  = 50 * time.Millisecond
+)
+
+// Postgres SQLSTATE codes for transient transaction conflicts
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// isRetryableWriteError reports whether err is a serialization failure or a deadlock
+func isRetryableWriteError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
+
+// execWithRetry runs a write statement, retrying with a small linear backoff when
+// Postgres aborts it with a serialization failure or a deadlock
+func (idx *Indexer) execWithRetry(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var (
+		result sql.Result
+		err    error
+	)
+	for attempt := 1; attempt <= maxWriteAttempts; attempt++ {
+		result, err = idx.db.ExecContext(ctx, query, args...)
+		if err == nil || !isRetryableWriteError(err) || attempt == maxWriteAttempts {
+			break
+		}
+
+		log.Printf("Retrying write after transient conflict (attempt %d/%d): %v", attempt, maxWriteAttempts, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(attempt) * writeRetryDelay):
+		}
+	}
+	return result, err
+}
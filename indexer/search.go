@@ -0,0 +1,44 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CountDetailsMatches returns the number of blocks whose details contain the JSON fragment
+func (idx *Indexer) CountDetailsMatches(fragment json.RawMessage) (int64, error) {
+	var count int64
+	if err := idx.readDB.QueryRow("SELECT COUNT(*) FROM blocks WHERE details @> $1::jsonb", string(fragment)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting block details matches: %w", err)
+	}
+	return count, nil
+}
+
+// SearchDetails returns the blocks whose details contain the JSON fragment (details @> fragment),
+// latest first, skipping the large columns fields does not project
+func (idx *Indexer) SearchDetails(fragment json.RawMessage, limit, offset int, fields []string) ([]BlockDetails, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT `+blockColumnsFor(fields)+`
+		FROM blocks
+		WHERE details @> $1::jsonb
+		ORDER BY block_height DESC
+		LIMIT $2 OFFSET $3`, string(fragment), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error searching block details: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []BlockDetails{}
+	for rows.Next() {
+		blockDetails, err := scanBlock(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning block: %w", err)
+		}
+		blocks = append(blocks, blockDetails)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blocks: %w", err)
+	}
+
+	return blocks, nil
+}
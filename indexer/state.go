@@ -0,0 +1,108 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Keys of the indexer_state table
+const (
+	// stateBackfillFrontier is the highest height the backfill still has to index; every height
+	// above it, up to stateBackfillTop, has been processed
+	stateBackfillFrontier = "backfill_frontier"
+	// stateBackfillTop is the height the backfill started from
+	stateBackfillTop = "backfill_top"
+)
+
+// frontierSaveInterval throttles the writes of the backfill frontier
+const frontierSaveInterval = 5 * time.Second
+
+// getState reads a value of the indexer_state table, reporting whether it is set
+func (idx *Indexer) getState(key string) (int64, bool, error) {
+	var value int64
+	err := idx.db.QueryRow("SELECT value FROM indexer_state WHERE key = $1", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("error reading indexer state %q: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// setState stores a value of the indexer_state table
+func (idx *Indexer) setState(key string, value int64) error {
+	_, err := idx.execWithRetry(context.Background(), `
+		INSERT INTO indexer_state (key, value, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at`,
+		key, value, time.Now())
+	if err != nil {
+		return fmt.Errorf("error storing indexer state %q: %w", key, err)
+	}
+	return nil
+}
+
+// loadBackfill returns the stored backfill frontier and top, if any
+func (idx *Indexer) loadBackfill() (frontier, top int64, found bool, err error) {
+	frontier, found, err = idx.getState(stateBackfillFrontier)
+	if err != nil || !found {
+		return 0, 0, false, err
+	}
+	top, found, err = idx.getState(stateBackfillTop)
+	if err != nil || !found {
+		return 0, 0, false, err
+	}
+	return frontier, top, true, nil
+}
+
+// frontierTracker follows the backfill frontier of a descending range whose heights complete out of
+// order, persisting it to indexer_state at most once per frontierSaveInterval
+type frontierTracker struct {
+	idx *Indexer
+
+	mu        sync.Mutex
+	frontier  int64
+	completed map[int64]bool
+	savedAt   time.Time
+}
+
+// newFrontierTracker tracks a backfill starting at frontier
+func (idx *Indexer) newFrontierTracker(frontier int64) *frontierTracker {
+	idx.backfillFrontier.Store(frontier)
+	return &frontierTracker{idx: idx, frontier: frontier, completed: map[int64]bool{}, savedAt: time.Now()}
+}
+
+// complete marks height as processed, moving the frontier down past every contiguous processed height
+func (t *frontierTracker) complete(height int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.completed[height] = true
+	for t.completed[t.frontier] {
+		delete(t.completed, t.frontier)
+		t.frontier--
+	}
+	t.idx.backfillFrontier.Store(t.frontier)
+
+	if time.Since(t.savedAt) >= frontierSaveInterval {
+		t.save()
+	}
+}
+
+// flush persists the current frontier
+func (t *frontierTracker) flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.save()
+}
+
+func (t *frontierTracker) save() {
+	if err := t.idx.setState(stateBackfillFrontier, t.frontier); err != nil {
+		log.Printf("Error saving backfill frontier: %v", err)
+	}
+	t.savedAt = time.Now()
+}
@@ -0,0 +1,55 @@
+//go:build integration
+
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+func TestTipOnlyCyclesIndexNewBlocks(t *testing.T) {
+	node := testrpc.NewNode(t)
+	for height := int64(1); height <= 13; height++ {
+		block := testRPCBlock
+		block.Height = height
+		block.Hash = fmt.Sprintf("%064X", height)
+		node.AddBlock(block)
+	}
+	setTip := func(height int64) {
+		node.Handle("/cosmos/base/tendermint/v1beta1/blocks/latest", fmt.Sprintf(`{"block":{"header":{"height":"%d"}}}`, height))
+	}
+	t.Setenv("RPC_URL", node.URL)
+	t.Setenv("REST_URL", node.URL)
+	t.Setenv("MODE", ModeTipOnly)
+	idx := newTestIndexer(t)
+
+	// The first cycle only records the checkpoint; the blocks below it are left to the warmup
+	setTip(10)
+	if err := idx.StartIndexing(context.Background(), 1, 10); err != nil {
+		t.Fatalf("first cycle: %v", err)
+	}
+	idx.Drain(10 * time.Second)
+	if heights := indexedHeights(t, idx); len(heights) != 0 {
+		t.Fatalf("first tip-only cycle indexed %v, want nothing", heights)
+	}
+
+	// The next cycle resumes from the checkpoint instead of starting over at the tip
+	setTip(13)
+	if err := idx.StartIndexing(context.Background(), 1, 10); err != nil {
+		t.Fatalf("second cycle: %v", err)
+	}
+	idx.Drain(10 * time.Second)
+	if heights := indexedHeights(t, idx); !reflect.DeepEqual(heights, []int64{11, 12, 13}) {
+		t.Fatalf("second tip-only cycle indexed %v, want [11 12 13]", heights)
+	}
+
+	top, found, err := idx.getState(stateBackfillTop)
+	if err != nil || !found || top != 13 {
+		t.Errorf("backfill top = %d, %v, %v; want 13", top, found, err)
+	}
+}
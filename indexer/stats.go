@@ -0,0 +1,450 @@
+package indexer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lib/pq"
+)
+
+// SizeStats represents aggregate block size statistics over a height range
+type SizeStats struct {
+	From         int64   `json:"from"`
+	To           int64   `json:"to"`
+	Blocks       int64   `json:"blocks"`
+	TotalBytes   int64   `json:"total_bytes"`
+	AverageBytes float64 `json:"average_bytes"`
+}
+
+// GetSizeStats returns the total and average block size for indexed blocks between from and to (inclusive)
+func (idx *Indexer) GetSizeStats(from, to int64) (*SizeStats, error) {
+	stats := SizeStats{From: from, To: to}
+	err := idx.readDB.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(block_size_bytes), 0), COALESCE(AVG(block_size_bytes), 0)
+		FROM blocks
+		WHERE block_height BETWEEN $1 AND $2 AND block_size_bytes IS NOT NULL`, from, to).Scan(
+		&stats.Blocks,
+		&stats.TotalBytes,
+		&stats.AverageBytes,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching size stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// GasStats represents aggregate gas statistics over a height range
+type GasStats struct {
+	From           int64   `json:"from"`
+	To             int64   `json:"to"`
+	Blocks         int64   `json:"blocks"`
+	TotalGasUsed   int64   `json:"total_gas_used"`
+	TotalGasWanted int64   `json:"total_gas_wanted"`
+	AverageUsed    float64 `json:"average_gas_used"`
+	AverageWanted  float64 `json:"average_gas_wanted"`
+}
+
+// GetGasStats returns the total and average gas used and wanted per block for indexed blocks between from and to (inclusive)
+func (idx *Indexer) GetGasStats(from, to int64) (*GasStats, error) {
+	stats := GasStats{From: from, To: to}
+	err := idx.readDB.QueryRow(`
+		SELECT COUNT(*),
+			COALESCE(SUM(total_gas_used), 0), COALESCE(SUM(total_gas_wanted), 0),
+			COALESCE(AVG(total_gas_used), 0), COALESCE(AVG(total_gas_wanted), 0)
+		FROM blocks
+		WHERE block_height BETWEEN $1 AND $2 AND total_gas_used IS NOT NULL`, from, to).Scan(
+		&stats.Blocks,
+		&stats.TotalGasUsed,
+		&stats.TotalGasWanted,
+		&stats.AverageUsed,
+		&stats.AverageWanted,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching gas stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// HourlyCount represents the number of blocks produced during an hour of the day (UTC)
+type HourlyCount struct {
+	Hour   int   `json:"hour"`
+	Blocks int64 `json:"blocks"`
+}
+
+// GetHourlyDistribution returns the number of indexed blocks between from and to (inclusive) per
+// UTC hour of the day, with all 24 hours present. Blocks without block_time are excluded.
+func (idx *Indexer) GetHourlyDistribution(from, to int64) ([]HourlyCount, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT EXTRACT(HOUR FROM block_time AT TIME ZONE 'UTC')::INT AS hour, COUNT(*)
+		FROM blocks
+		WHERE block_height BETWEEN $1 AND $2 AND block_time IS NOT NULL
+		GROUP BY hour`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching hourly distribution: %w", err)
+	}
+	defer rows.Close()
+
+	distribution := make([]HourlyCount, 24)
+	for hour := range distribution {
+		distribution[hour].Hour = hour
+	}
+	for rows.Next() {
+		var (
+			hour   int
+			blocks int64
+		)
+		if err := rows.Scan(&hour, &blocks); err != nil {
+			return nil, fmt.Errorf("error scanning hourly distribution: %w", err)
+		}
+		if hour >= 0 && hour < 24 {
+			distribution[hour].Blocks = blocks
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hourly distribution: %w", err)
+	}
+
+	return distribution, nil
+}
+
+// ProposerStats represents the number of blocks proposed by a single proposer
+type ProposerStats struct {
+	Proposer string `json:"proposer"`
+	Blocks   int64  `json:"blocks"`
+}
+
+// CountProposers returns the number of proposers GetProposerStats lists between from and to (inclusive)
+func (idx *Indexer) CountProposers(from, to int64) (int64, error) {
+	var count int64
+	err := idx.readDB.QueryRow(`
+		SELECT COUNT(DISTINCT proposer_address)
+		FROM blocks
+		WHERE block_height BETWEEN $1 AND $2 AND proposer_address IS NOT NULL AND proposer_address <> ''`, from, to).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting proposers: %w", err)
+	}
+	return count, nil
+}
+
+// GetProposerStats returns the block count per proposer between from and to (inclusive), leaving out
+// blocks stored without a proposer. Proposers with equal counts are ordered by address so pages are
+// stable across calls.
+func (idx *Indexer) GetProposerStats(from, to int64, limit, offset int) ([]ProposerStats, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT proposer_address, COUNT(*) AS blocks
+		FROM blocks
+		WHERE block_height BETWEEN $1 AND $2 AND proposer_address IS NOT NULL AND proposer_address <> ''
+		GROUP BY proposer_address
+		ORDER BY blocks DESC, proposer_address ASC
+		LIMIT $3 OFFSET $4`, from, to, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching proposer stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []ProposerStats{}
+	for rows.Next() {
+		var s ProposerStats
+		if err := rows.Scan(&s.Proposer, &s.Blocks); err != nil {
+			return nil, fmt.Errorf("error scanning proposer stats: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proposer stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ProposerShare represents a proposer's share of the blocks in a range
+type ProposerShare struct {
+	Proposer string  `json:"proposer"`
+	Blocks   int64   `json:"blocks"`
+	Share    float64 `json:"share"`
+}
+
+// ProposerDistribution represents decentralization metrics over the proposers of a range
+type ProposerDistribution struct {
+	From        int64           `json:"from"`
+	To          int64           `json:"to"`
+	TotalBlocks int64           `json:"total_blocks"`
+	Proposers   []ProposerShare `json:"proposers"`
+	// Nakamoto33 and Nakamoto50 are the minimum number of proposers controlling more than 33% and 50% of the blocks
+	Nakamoto33 int     `json:"nakamoto_33"`
+	Nakamoto50 int     `json:"nakamoto_50"`
+	Gini       float64 `json:"gini"`
+}
+
+// GetProposerDistribution returns each proposer's share of blocks between from and to (inclusive)
+// along with the Nakamoto coefficients and the Gini coefficient of the shares
+func (idx *Indexer) GetProposerDistribution(from, to int64) (*ProposerDistribution, error) {
+	stats, err := idx.GetProposerStats(from, to, math.MaxInt32, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	dist := ProposerDistribution{From: from, To: to, Proposers: []ProposerShare{}}
+	for _, s := range stats {
+		dist.TotalBlocks += s.Blocks
+	}
+	if dist.TotalBlocks == 0 {
+		return &dist, nil
+	}
+
+	// Stats are ordered by block count descending, so the running share gives the Nakamoto coefficients
+	var cumulative float64
+	for i, s := range stats {
+		share := float64(s.Blocks) / float64(dist.TotalBlocks)
+		dist.Proposers = append(dist.Proposers, ProposerShare{Proposer: s.Proposer, Blocks: s.Blocks, Share: share})
+
+		cumulative += share
+		if dist.Nakamoto33 == 0 && cumulative > 1.0/3 {
+			dist.Nakamoto33 = i + 1
+		}
+		if dist.Nakamoto50 == 0 && cumulative > 0.5 {
+			dist.Nakamoto50 = i + 1
+		}
+	}
+	dist.Gini = gini(stats)
+
+	return &dist, nil
+}
+
+// gini computes the Gini coefficient of block counts sorted in descending order
+func gini(stats []ProposerStats) float64 {
+	n := len(stats)
+	var total, weighted float64
+	for i, s := range stats {
+		// Rank in ascending order is n-i for a descending slice
+		total += float64(s.Blocks)
+		weighted += float64(n-i) * float64(s.Blocks)
+	}
+	if n == 0 || total == 0 {
+		return 0
+	}
+	return (2*weighted)/(float64(n)*total) - float64(n+1)/float64(n)
+}
+
+// TxTypeCount represents the number of messages of a single type
+type TxTypeCount struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+}
+
+// GetTxTypeStats returns the number of messages per type in the txs of blocks between from and to (inclusive)
+func (idx *Indexer) GetTxTypeStats(from, to int64) ([]TxTypeCount, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT t.key, SUM(t.value::BIGINT) AS count
+		FROM blocks, jsonb_each_text(blocks.tx_message_types) t
+		WHERE block_height BETWEEN $1 AND $2
+		GROUP BY t.key
+		ORDER BY count DESC, t.key ASC`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tx type stats: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []TxTypeCount{}
+	for rows.Next() {
+		var tc TxTypeCount
+		if err := rows.Scan(&tc.Type, &tc.Count); err != nil {
+			return nil, fmt.Errorf("error scanning tx type stats: %w", err)
+		}
+		counts = append(counts, tc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tx type stats: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ValidatorParticipation represents a bonded validator's proposals within a window
+type ValidatorParticipation struct {
+	Validator
+	Blocks int64 `json:"blocks"`
+	Absent bool  `json:"absent"`
+}
+
+// Participation represents which bonded validators proposed within the latest indexed blocks
+type Participation struct {
+	Window     int                      `json:"window"`
+	From       int64                    `json:"from"`
+	To         int64                    `json:"to"`
+	Validators []ValidatorParticipation `json:"validators"`
+	Absent     int                      `json:"absent"`
+	// UnknownProposers proposed in the window but are not in the current bonded set
+	UnknownProposers []ProposerStats `json:"unknown_proposers"`
+}
+
+// GetParticipation returns, for the latest window indexed blocks, how many blocks each bonded
+// validator proposed, flagging validators that proposed none. Blocks stored without a proposer
+// count towards the window but are credited to no one.
+func (idx *Indexer) GetParticipation(window int) (*Participation, error) {
+	validators, err := idx.GetValidators()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching validator set: %w", err)
+	}
+
+	rows, err := idx.readDB.Query(`
+		SELECT proposer_address, COUNT(*) AS blocks, MIN(block_height), MAX(block_height)
+		FROM (SELECT block_height, proposer_address FROM blocks ORDER BY block_height DESC LIMIT $1) w
+		WHERE proposer_address IS NOT NULL AND proposer_address <> ''
+		GROUP BY proposer_address
+		ORDER BY blocks DESC, proposer_address ASC`, window)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching window proposers: %w", err)
+	}
+	defer rows.Close()
+
+	participation := Participation{Window: window, UnknownProposers: []ProposerStats{}}
+	proposed := map[string]int64{}
+	var order []string
+	for rows.Next() {
+		var (
+			s               ProposerStats
+			lowest, highest int64
+		)
+		if err := rows.Scan(&s.Proposer, &s.Blocks, &lowest, &highest); err != nil {
+			return nil, fmt.Errorf("error scanning window proposers: %w", err)
+		}
+		if participation.From == 0 || lowest < participation.From {
+			participation.From = lowest
+		}
+		if highest > participation.To {
+			participation.To = highest
+		}
+		proposed[s.Proposer] = s.Blocks
+		order = append(order, s.Proposer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating window proposers: %w", err)
+	}
+
+	known := map[string]bool{}
+	for _, v := range validators {
+		known[v.ConsensusAddress] = true
+		blocks := proposed[v.ConsensusAddress]
+		participation.Validators = append(participation.Validators, ValidatorParticipation{
+			Validator: v,
+			Blocks:    blocks,
+			Absent:    blocks == 0,
+		})
+		if blocks == 0 {
+			participation.Absent++
+		}
+	}
+	for _, proposer := range order {
+		if !known[proposer] {
+			participation.UnknownProposers = append(participation.UnknownProposers, ProposerStats{Proposer: proposer, Blocks: proposed[proposer]})
+		}
+	}
+
+	return &participation, nil
+}
+
+// HistogramBucket represents the number of blocks whose transaction count falls in [Min, Max]
+type HistogramBucket struct {
+	Label  string `json:"label"`
+	Min    int64  `json:"min"`
+	Max    *int64 `json:"max"`
+	Blocks int64  `json:"blocks"`
+}
+
+// GetTxHistogram returns the distribution of transactions per block between from and to (inclusive).
+// bounds are the ascending inclusive upper bounds of the buckets; a final open bucket holds larger counts.
+func (idx *Indexer) GetTxHistogram(from, to int64, bounds []int64) ([]HistogramBucket, error) {
+	// width_bucket takes the lower bound of every bucket but the first
+	thresholds := make([]int64, len(bounds))
+	for i, bound := range bounds {
+		thresholds[i] = bound + 1
+	}
+
+	rows, err := idx.readDB.Query(`
+		SELECT width_bucket(COALESCE(num_transactions, 0), $3::BIGINT[]) AS bucket, COUNT(*)
+		FROM blocks
+		WHERE block_height BETWEEN $1 AND $2
+		GROUP BY bucket`, from, to, pq.Array(thresholds))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tx histogram: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := make([]HistogramBucket, len(bounds)+1)
+	var lower int64
+	for i := range buckets {
+		buckets[i].Min = lower
+		if i < len(bounds) {
+			upper := bounds[i]
+			buckets[i].Max = &upper
+			buckets[i].Label = fmt.Sprintf("%d-%d", lower, upper)
+			if lower == upper {
+				buckets[i].Label = fmt.Sprint(upper)
+			}
+			lower = upper + 1
+		} else {
+			buckets[i].Label = fmt.Sprintf("%d+", lower)
+		}
+	}
+
+	for rows.Next() {
+		var (
+			bucket int
+			count  int64
+		)
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("error scanning tx histogram: %w", err)
+		}
+		buckets[bucket].Blocks = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tx histogram: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// SlowBlock is a block produced more than a threshold after the previous height
+type SlowBlock struct {
+	Height          int64   `json:"height"`
+	PreviousHeight  int64   `json:"previous_height"`
+	IntervalSeconds float64 `json:"interval_seconds"`
+}
+
+// GetSlowBlocks returns the blocks between from and to (inclusive) whose block time is more than threshold
+// seconds after the block time of the previous height, in ascending height order. Only consecutive indexed heights
+// are compared, so gaps in the index and blocks without block_time are skipped.
+func (idx *Indexer) GetSlowBlocks(from, to int64, threshold float64) ([]SlowBlock, error) {
+	rows, err := idx.readDB.Query(`
+		SELECT block_height, prev_height, EXTRACT(EPOCH FROM block_time - prev_time)::FLOAT8 AS interval
+		FROM (
+			SELECT block_height, block_time,
+				LAG(block_height) OVER (ORDER BY block_height) AS prev_height,
+				LAG(block_time) OVER (ORDER BY block_height) AS prev_time
+			FROM blocks
+			WHERE block_height BETWEEN $1 AND $2
+		) b
+		WHERE prev_height = block_height - 1 AND EXTRACT(EPOCH FROM block_time - prev_time) > $3
+		ORDER BY block_height`, from, to, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching slow blocks: %w", err)
+	}
+	defer rows.Close()
+
+	slow := []SlowBlock{}
+	for rows.Next() {
+		var block SlowBlock
+		if err := rows.Scan(&block.Height, &block.PreviousHeight, &block.IntervalSeconds); err != nil {
+			return nil, fmt.Errorf("error scanning slow block: %w", err)
+		}
+		slow = append(slow, block)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating slow blocks: %w", err)
+	}
+
+	return slow, nil
+}
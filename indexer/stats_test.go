@@ -0,0 +1,155 @@
+//go:build integration
+
+package indexer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+func TestAggregateOrderingIsStable(t *testing.T) {
+	idx := newTestIndexer(t)
+
+	proposer := func(c string) string { return strings.Repeat(c, 40) }
+	// Inserted out of address order, so only the tie-breaker orders equal counts
+	proposers := []string{"D", "B", "E", "D", "C", "B", "A", "D", "C", "B", "A"}
+	for i, p := range proposers {
+		insertBlock(t, idx, BlockDetails{
+			Height:   int64(i + 1),
+			BlockID:  fmt.Sprintf("%064X", i+1),
+			Proposer: proposer(p),
+			// Every message type is counted once per block, so types tie too
+			TxMessageTypes: map[string]int{"/cosmos.bank.v1beta1.MsgSend": 1, "/cosmos.authz.v1beta1.MsgExec": 1},
+		})
+	}
+	from, to := int64(1), int64(len(proposers))
+
+	wantStats := []ProposerStats{
+		{proposer("B"), 3}, {proposer("D"), 3}, {proposer("A"), 2}, {proposer("C"), 2}, {proposer("E"), 1},
+	}
+	wantTypes := []TxTypeCount{
+		{"/cosmos.authz.v1beta1.MsgExec", int64(len(proposers))}, {"/cosmos.bank.v1beta1.MsgSend", int64(len(proposers))},
+	}
+
+	for call := 0; call < 5; call++ {
+		stats, err := idx.GetProposerStats(from, to, 100, 0)
+		if err != nil {
+			t.Fatalf("GetProposerStats: %v", err)
+		}
+		if !reflect.DeepEqual(stats, wantStats) {
+			t.Fatalf("call %d: GetProposerStats = %v, want %v", call, stats, wantStats)
+		}
+
+		// Pages of tied proposers neither repeat nor skip any of them
+		var paged []ProposerStats
+		for offset := 0; offset < len(wantStats); offset += 2 {
+			page, err := idx.GetProposerStats(from, to, 2, offset)
+			if err != nil {
+				t.Fatalf("GetProposerStats page: %v", err)
+			}
+			paged = append(paged, page...)
+		}
+		if !reflect.DeepEqual(paged, wantStats) {
+			t.Fatalf("call %d: paged GetProposerStats = %v, want %v", call, paged, wantStats)
+		}
+
+		types, err := idx.GetTxTypeStats(from, to)
+		if err != nil {
+			t.Fatalf("GetTxTypeStats: %v", err)
+		}
+		if !reflect.DeepEqual(types, wantTypes) {
+			t.Fatalf("call %d: GetTxTypeStats = %v, want %v", call, types, wantTypes)
+		}
+	}
+}
+
+func TestNullTransactionCountsAreZero(t *testing.T) {
+	idx := newTestIndexer(t)
+	// Rows stored before the migration made the column NOT NULL, e.g. on a replica lagging behind it
+	if _, err := idx.db.Exec(`ALTER TABLE blocks ALTER COLUMN num_transactions DROP NOT NULL`); err != nil {
+		t.Fatal(err)
+	}
+	for height, txs := range []int{0, 3, 7} {
+		insertBlock(t, idx, BlockDetails{Height: int64(height + 1), NumTransactions: txs})
+	}
+	insertBlock(t, idx, BlockDetails{Height: 4})
+	if _, err := idx.db.Exec(`UPDATE blocks SET num_transactions = NULL WHERE block_height = 4`); err != nil {
+		t.Fatal(err)
+	}
+
+	buckets, err := idx.GetTxHistogram(1, 4, []int64{0, 4})
+	if err != nil {
+		t.Fatalf("GetTxHistogram: %v", err)
+	}
+	var counts []int64
+	for _, bucket := range buckets {
+		counts = append(counts, bucket.Blocks)
+	}
+	// 0 and NULL, 3, 7
+	if !reflect.DeepEqual(counts, []int64{2, 1, 1}) {
+		t.Errorf("histogram counts = %v, want [2 1 1]", counts)
+	}
+
+	block, _, err := idx.GetBlockDetails(4)
+	if err != nil {
+		t.Fatalf("GetBlockDetails: %v", err)
+	}
+	if block.NumTransactions != 0 {
+		t.Errorf("num_transactions = %d, want 0", block.NumTransactions)
+	}
+	blocks, err := idx.ListBlocks(BlockFilter{}, SortTxsDesc, 10, 0, nil)
+	if err != nil {
+		t.Fatalf("ListBlocks: %v", err)
+	}
+	if len(blocks) != 4 || blocks[0].NumTransactions != 7 || blocks[3].NumTransactions != 0 {
+		t.Errorf("blocks by transactions = %+v", blocks)
+	}
+}
+
+func TestProposerStatsSkipMissingProposers(t *testing.T) {
+	idx := newTestIndexer(t)
+	proposer := strings.Repeat("A", 40)
+	insertBlock(t, idx, BlockDetails{Height: 1, Proposer: proposer})
+	// Stored with a NULL, then an empty proposer
+	insertBlock(t, idx, BlockDetails{Height: 2})
+	insertBlock(t, idx, BlockDetails{Height: 3})
+	if _, err := idx.db.Exec(`UPDATE blocks SET proposer_address = '' WHERE block_height = 3`); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := idx.GetProposerStats(1, 3, 10, 0)
+	if err != nil {
+		t.Fatalf("GetProposerStats: %v", err)
+	}
+	if want := []ProposerStats{{proposer, 1}}; !reflect.DeepEqual(stats, want) {
+		t.Errorf("GetProposerStats = %v, want %v", stats, want)
+	}
+}
+
+func TestParticipationSkipsMissingProposers(t *testing.T) {
+	node := testrpc.NewNode(t)
+	node.Handle("/cosmos/staking/v1beta1/validators?pagination.limit=500&status=BOND_STATUS_BONDED", `{"validators":[],"pagination":{"next_key":null}}`)
+	t.Setenv("REST_URL", node.URL)
+	idx := newTestIndexer(t)
+
+	proposer := strings.Repeat("A", 40)
+	insertBlock(t, idx, BlockDetails{Height: 1, Proposer: proposer})
+	// Stored with a NULL, then an empty proposer
+	insertBlock(t, idx, BlockDetails{Height: 2})
+	insertBlock(t, idx, BlockDetails{Height: 3})
+	if _, err := idx.db.Exec(`UPDATE blocks SET proposer_address = '' WHERE block_height = 3`); err != nil {
+		t.Fatal(err)
+	}
+
+	participation, err := idx.GetParticipation(10)
+	if err != nil {
+		t.Fatalf("GetParticipation: %v", err)
+	}
+	if want := []ProposerStats{{proposer, 1}}; !reflect.DeepEqual(participation.UnknownProposers, want) {
+		t.Errorf("unknown proposers = %v, want %v", participation.UnknownProposers, want)
+	}
+}
@@ -0,0 +1,152 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Tip modes selectable through TIP_MODE
+const (
+	TipModePoll      = "poll"
+	TipModeWebsocket = "websocket"
+)
+
+// Timings of the new block subscription
+const (
+	// subscriptionReadTimeout drops a subscription that delivered nothing, not even a ping, for this long
+	subscriptionReadTimeout = time.Minute
+	// subscriptionRetryDelay is the pause before re-subscribing after the subscription dropped
+	subscriptionRetryDelay = 10 * time.Second
+)
+
+// newBlockQuery is the Tendermint event query of new blocks
+const newBlockQuery = "tm.event='NewBlock'"
+
+// newBlockEvent is the part of a NewBlock event message read by the indexer
+type newBlockEvent struct {
+	Result struct {
+		Data struct {
+			Value struct {
+				Block struct {
+					Header struct {
+						Height string `json:"height"`
+					} `json:"header"`
+				} `json:"block"`
+			} `json:"value"`
+		} `json:"data"`
+	} `json:"result"`
+	Error interface{} `json:"error"`
+}
+
+// Subscribed reports whether new blocks are currently received over the WebSocket subscription
+func (idx *Indexer) Subscribed() bool {
+	return idx.subscribed.Load()
+}
+
+// SubscriptionLost signals, when TIP_MODE=websocket, that the subscription dropped so polling resumes right away
+func (idx *Indexer) SubscriptionLost() <-chan struct{} {
+	return idx.subscriptionLost
+}
+
+// RunSubscription indexes new blocks as the RPC WebSocket announces them until ctx is cancelled,
+// re-subscribing after subscriptionRetryDelay whenever the subscription drops
+func (idx *Indexer) RunSubscription(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := idx.subscribeNewBlocks(ctx)
+		if idx.subscribed.Swap(false) {
+			// Wake up the polling loop
+			select {
+			case idx.subscriptionLost <- struct{}{}:
+			default:
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("New block subscription dropped, falling back to polling: %v", err)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(subscriptionRetryDelay):
+		}
+	}
+}
+
+// subscribeNewBlocks subscribes to NewBlock events and indexes each announced height until the connection fails
+func (idx *Indexer) subscribeNewBlocks(ctx context.Context) error {
+	e := idx.rpc.pick()
+	url := strings.Replace(e.url, "http", "ws", 1) + "/websocket"
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		err = fmt.Errorf("error connecting to %s: %w", url, err)
+		idx.rpc.report(e, err)
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock ReadJSON on shutdown
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	err = conn.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "subscribe",
+		"id":      1,
+		"params":  map[string]string{"query": newBlockQuery},
+	})
+	if err != nil {
+		return fmt.Errorf("error subscribing to new blocks: %w", err)
+	}
+
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(subscriptionReadTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	for {
+		conn.SetReadDeadline(time.Now().Add(subscriptionReadTimeout))
+
+		var event newBlockEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			return fmt.Errorf("error reading new block event: %w", err)
+		}
+		if event.Error != nil {
+			return rpcError(event.Error)
+		}
+		// The subscription confirmation has an empty result
+		heightStr := event.Result.Data.Value.Block.Header.Height
+		if heightStr == "" {
+			if !idx.subscribed.Swap(true) {
+				log.Printf("Subscribed to new blocks on %s", url)
+			}
+			continue
+		}
+
+		height, err := strconv.ParseInt(heightStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing new block height: %w", err)
+		}
+		idx.observeChainHeight(height)
+		// Index the block that just reached CONFIRMATION_DEPTH rather than the announced one
+		if height = idx.ConfirmedHeight(height); height == 0 {
+			continue
+		}
+		go func() {
+			if _, err := idx.FetchAndStoreBlockDetails(ctx, height); err != nil {
+				log.Printf("Error indexing new block %d: %v", height, err)
+			}
+		}()
+	}
+}
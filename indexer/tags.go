@@ -0,0 +1,45 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AddBlockTags tags the block at height, ignoring tags it already has, and returns all its tags
+func (idx *Indexer) AddBlockTags(ctx context.Context, height int64, tags []string) ([]string, error) {
+	_, err := idx.execWithRetry(ctx, `
+		INSERT INTO block_tags (block_height, tag, created_at)
+		SELECT $1, tag, $3 FROM UNNEST($2::text[]) AS tag
+		ON CONFLICT (block_height, tag) DO NOTHING`, height, pq.Array(tags), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error storing block tags: %w", err)
+	}
+
+	return idx.GetBlockTags(ctx, height)
+}
+
+// GetBlockTags returns the tags of the block at height, in alphabetical order
+func (idx *Indexer) GetBlockTags(ctx context.Context, height int64) ([]string, error) {
+	rows, err := idx.db.QueryContext(ctx, "SELECT tag FROM block_tags WHERE block_height = $1 ORDER BY tag", height)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching block tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("error scanning block tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating block tags: %w", err)
+	}
+
+	return tags, nil
+}
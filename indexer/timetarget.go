@@ -0,0 +1,56 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HeightAtTime returns the lowest height whose block time is at or after t, binary searching the
+// chain between height 1 and the latest height. Block times are read from the database when the
+// block is indexed and from the RPC otherwise; heights pruned by the node count as before t.
+func (idx *Indexer) HeightAtTime(t time.Time) (int64, error) {
+	latest, err := idx.LatestHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	lo, hi := int64(1), latest
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		blockTime, err := idx.blockTime(mid)
+		if errors.Is(err, ErrBlockPruned) {
+			lo = mid + 1
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error finding block time of height %d: %w", mid, err)
+		}
+		if blockTime.Before(t) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// blockTime returns the time of the block at height, from the database when indexed, else from the RPC
+func (idx *Indexer) blockTime(height int64) (time.Time, error) {
+	var blockTime sql.NullTime
+	err := idx.db.QueryRow("SELECT block_time FROM blocks WHERE block_height = $1", height).Scan(&blockTime)
+	if err == nil && blockTime.Valid {
+		return blockTime.Time, nil
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return time.Time{}, fmt.Errorf("error fetching block time from database: %w", err)
+	}
+
+	block, err := idx.getBlock(context.Background(), height)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return block.BlockTime, nil
+}
@@ -0,0 +1,111 @@
+package indexer
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// unknownMessageType is reported for txs that cannot be decoded as a cosmos TxRaw
+const unknownMessageType = "unknown"
+
+// decodeTxs returns the base64-decoded txs from block.data.txs
+func decodeTxs(block map[string]interface{}) ([][]byte, error) {
+	data, ok := block["data"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("'data' field not found")
+	}
+
+	var decoded [][]byte
+	switch txs := data["txs"].(type) {
+	case []interface{}:
+		for i, tx := range txs {
+			encoded, ok := tx.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected type for tx %d: %T", i, tx)
+			}
+			raw, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding tx %d: %w", i, err)
+			}
+			decoded = append(decoded, raw)
+		}
+	case nil:
+		// Empty blocks report a null txs array
+	default:
+		return nil, fmt.Errorf("unexpected type for txs: %T", txs)
+	}
+
+	return decoded, nil
+}
+
+// countMessageTypes returns the number of messages per type URL (e.g. /cosmos.bank.v1beta1.MsgSend) in txs
+func countMessageTypes(txs [][]byte) map[string]int {
+	counts := map[string]int{}
+	for _, tx := range txs {
+		types, err := txMessageTypes(tx)
+		if err != nil {
+			counts[unknownMessageType]++
+			continue
+		}
+		for _, t := range types {
+			counts[t]++
+		}
+	}
+	return counts
+}
+
+// txMessageTypes returns the message type URLs of a protobuf-encoded cosmos TxRaw
+func txMessageTypes(tx []byte) ([]string, error) {
+	// TxRaw.body_bytes = 1
+	bodies, err := bytesFields(tx, 1)
+	if err != nil || len(bodies) != 1 {
+		return nil, fmt.Errorf("invalid TxRaw")
+	}
+	// TxBody.messages = 1
+	messages, err := bytesFields(bodies[0], 1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TxBody: %w", err)
+	}
+
+	types := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		// Any.type_url = 1
+		typeURLs, err := bytesFields(msg, 1)
+		if err != nil || len(typeURLs) != 1 {
+			return nil, fmt.Errorf("invalid message Any")
+		}
+		types = append(types, string(typeURLs[0]))
+	}
+	return types, nil
+}
+
+// bytesFields returns the values of every length-delimited field with the given number in a protobuf message
+func bytesFields(b []byte, num protowire.Number) ([][]byte, error) {
+	var values [][]byte
+	for len(b) > 0 {
+		n, typ, tagLen := protowire.ConsumeTag(b)
+		if tagLen < 0 {
+			return nil, protowire.ParseError(tagLen)
+		}
+		b = b[tagLen:]
+
+		if n == num && typ == protowire.BytesType {
+			value, valueLen := protowire.ConsumeBytes(b)
+			if valueLen < 0 {
+				return nil, protowire.ParseError(valueLen)
+			}
+			values = append(values, value)
+			b = b[valueLen:]
+			continue
+		}
+
+		valueLen := protowire.ConsumeFieldValue(n, typ, b)
+		if valueLen < 0 {
+			return nil, protowire.ParseError(valueLen)
+		}
+		b = b[valueLen:]
+	}
+	return values, nil
+}
@@ -0,0 +1,115 @@
+package indexer
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Validator represents a bonded validator from the staking REST API
+type Validator struct {
+	OperatorAddress string `json:"operator_address"`
+	Moniker         string `json:"moniker"`
+	// ConsensusAddress is the hex-encoded consensus address, as stored in proposer_address
+	ConsensusAddress string `json:"consensus_address"`
+}
+
+// validatorsResponse mirrors the /cosmos/staking/v1beta1/validators REST response
+type validatorsResponse struct {
+	Validators []struct {
+		OperatorAddress string `json:"operator_address"`
+		ConsensusPubkey struct {
+			Type string `json:"@type"`
+			Key  string `json:"key"`
+		} `json:"consensus_pubkey"`
+		Description struct {
+			Moniker string `json:"moniker"`
+		} `json:"description"`
+	} `json:"validators"`
+	Pagination struct {
+		NextKey string `json:"next_key"`
+	} `json:"pagination"`
+}
+
+// GetValidators fetches the bonded validator set from the staking REST API
+func (idx *Indexer) GetValidators() ([]Validator, error) {
+	return idx.fetchValidators("BOND_STATUS_BONDED")
+}
+
+// fetchValidators fetches the validators with the staking status from the staking REST API, or every
+// validator when status is empty
+func (idx *Indexer) fetchValidators(status string) ([]Validator, error) {
+	var (
+		validators []Validator
+		nextKey    string
+	)
+	for {
+		query := url.Values{}
+		if status != "" {
+			query.Set("status", status)
+		}
+		query.Set("pagination.limit", "500")
+		if nextKey != "" {
+			query.Set("pagination.key", nextKey)
+		}
+
+		page, err := idx.getValidatorsPage(idx.config.RESTURL + "/cosmos/staking/v1beta1/validators?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range page.Validators {
+			consensusAddress, err := consensusAddress(v.ConsensusPubkey.Type, v.ConsensusPubkey.Key)
+			if err != nil {
+				return nil, fmt.Errorf("error deriving consensus address of %s: %w", v.OperatorAddress, err)
+			}
+			validators = append(validators, Validator{
+				OperatorAddress:  v.OperatorAddress,
+				Moniker:          v.Description.Moniker,
+				ConsensusAddress: consensusAddress,
+			})
+		}
+
+		if page.Pagination.NextKey == "" {
+			return validators, nil
+		}
+		nextKey = page.Pagination.NextKey
+	}
+}
+
+// getValidatorsPage fetches and decodes a single page of the validators REST query
+func (idx *Indexer) getValidatorsPage(url string) (*validatorsResponse, error) {
+	resp, err := idx.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching validators from REST API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("REST API request failed with status code: %d", resp.StatusCode)
+	}
+
+	var page validatorsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("error decoding validators from REST API: %w", err)
+	}
+	return &page, nil
+}
+
+// consensusAddress derives the hex consensus address of an ed25519 consensus public key
+func consensusAddress(keyType, key string) (string, error) {
+	if !strings.HasSuffix(keyType, "ed25519.PubKey") {
+		return "", fmt.Errorf("unsupported consensus key type %q", keyType)
+	}
+	pubKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", fmt.Errorf("error decoding consensus key: %w", err)
+	}
+
+	hash := sha256.Sum256(pubKey)
+	return strings.ToUpper(hex.EncodeToString(hash[:20])), nil
+}
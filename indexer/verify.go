@@ -0,0 +1,50 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// FieldDiff holds the stored and the freshly fetched value of a mismatching field
+type FieldDiff struct {
+	Stored interface{} `json:"stored"`
+	Fresh  interface{} `json:"fresh"`
+}
+
+// BlockVerification is the result of comparing a stored block to a fresh fetch
+type BlockVerification struct {
+	Height  int64                `json:"height"`
+	Matches bool                 `json:"matches"`
+	Diff    map[string]FieldDiff `json:"diff"`
+}
+
+// VerifyBlock fetches a block fresh from the blockchain, without storing it, and compares its
+// block_id, proposer and number of transactions to the stored row
+func (idx *Indexer) VerifyBlock(height int64) (*BlockVerification, error) {
+	stored, err := scanBlock(idx.db.QueryRow("SELECT "+blockColumns+" FROM blocks WHERE block_height = $1", height))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("block %d is not indexed: %w", height, ErrBlockNotFound)
+		}
+		return nil, fmt.Errorf("error fetching block from database: %w", err)
+	}
+	fresh, err := idx.getBlockResults(context.Background(), height)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching block details: %w", err)
+	}
+
+	verification := BlockVerification{Height: height, Diff: map[string]FieldDiff{}}
+	if stored.BlockID != fresh.BlockID {
+		verification.Diff["block_id"] = FieldDiff{Stored: stored.BlockID, Fresh: fresh.BlockID}
+	}
+	if stored.Proposer != fresh.Proposer {
+		verification.Diff["proposer"] = FieldDiff{Stored: stored.Proposer, Fresh: fresh.Proposer}
+	}
+	if stored.NumTransactions != fresh.NumTransactions {
+		verification.Diff["num_transactions"] = FieldDiff{Stored: stored.NumTransactions, Fresh: fresh.NumTransactions}
+	}
+	verification.Matches = len(verification.Diff) == 0
+
+	return &verification, nil
+}
@@ -0,0 +1,45 @@
+package indexer
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Warmup indexes and stores the latest n blocks up to latestHeight before the backfill starts,
+// so the chain tip is queryable right after startup
+func (idx *Indexer) Warmup(latestHeight int64, n int64) {
+	if n <= 0 || latestHeight <= 0 {
+		return
+	}
+	from := latestHeight - n + 1
+	if from < 1 {
+		from = 1
+	}
+
+	log.Printf("Warming up: indexing blocks %d-%d", from, latestHeight)
+	start := time.Now()
+
+	var (
+		g      errgroup.Group
+		failed int64
+	)
+	g.SetLimit(idx.config.Concurrency)
+	for height := from; height <= latestHeight; height++ {
+		height := height
+		g.Go(func() error {
+			// Stored before returning, so the tip is queryable once Warmup returns
+			if _, err := idx.fetchAndStoreBlockDetailsNow(context.Background(), height, false); err != nil {
+				log.Printf("Error indexing block %d during warmup: %v", height, err)
+				atomic.AddInt64(&failed, 1)
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	log.Printf("Warmup indexed %d blocks in %s (%d failed)", latestHeight-from+1-failed, time.Since(start).Round(time.Millisecond), failed)
+}
@@ -0,0 +1,30 @@
+//go:build integration
+
+package indexer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/muhammadfarhankt/omniFlix/internal/testrpc"
+)
+
+func TestWarmupStoresBlocksBeforeReturning(t *testing.T) {
+	node := testrpc.NewNode(t)
+	for height := int64(8); height <= 10; height++ {
+		block := testRPCBlock
+		block.Height = height
+		block.Hash = fmt.Sprintf("%064X", height)
+		node.AddBlock(block)
+	}
+	t.Setenv("RPC_URL", node.URL)
+	idx := newTestIndexer(t)
+
+	idx.Warmup(10, 3)
+
+	// No Drain: the blocks are stored by the time Warmup returns
+	if heights := indexedHeights(t, idx); !reflect.DeepEqual(heights, []int64{8, 9, 10}) {
+		t.Errorf("indexed heights after warmup = %v, want [8 9 10]", heights)
+	}
+}
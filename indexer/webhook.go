@@ -0,0 +1,52 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Delivery settings of webhooks
+const (
+	webhookAttempts     = 3
+	webhookRetryBackoff = 2 * time.Second
+)
+
+// postWebhook POSTs payload as JSON to url, retrying failed deliveries with a growing backoff.
+// Failures are logged, never returned, so a down receiver cannot stall indexing.
+func (idx *Indexer) postWebhook(url string, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error encoding webhook payload: %v", err)
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = idx.deliverWebhook(url, body)
+		if err == nil {
+			return
+		}
+		if attempt == webhookAttempts {
+			log.Printf("Error delivering webhook to %s after %d attempts: %v", url, attempt, err)
+			return
+		}
+		time.Sleep(time.Duration(attempt) * webhookRetryBackoff)
+	}
+}
+
+// deliverWebhook makes a single webhook delivery attempt, failing on non-2xx responses
+func (idx *Indexer) deliverWebhook(url string, body []byte) error {
+	resp, err := idx.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package testdb provides the Postgres database of the integration tests, which are built with the
+// integration tag: go test -tags integration ./...
+package testdb
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/lib/pq"
+)
+
+// connStr is the connection string of the test database, set by Main
+var connStr string
+
+// Main runs the tests of a package against the database of TEST_DATABASE_URL when set, or against an
+// embedded Postgres started for the package and stopped once its tests are done. The embedded binaries
+// are downloaded on first use and cached; Postgres refuses to run as root, so use TEST_DATABASE_URL there.
+func Main(m *testing.M) int {
+	if dbURL := os.Getenv("TEST_DATABASE_URL"); dbURL != "" {
+		var err error
+		connStr, err = pq.ParseURL(dbURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error parsing TEST_DATABASE_URL: %v\n", err)
+			return 1
+		}
+		return m.Run()
+	}
+
+	stop, err := startEmbedded()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error starting embedded Postgres: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "error stopping embedded Postgres: %v\n", err)
+		}
+	}()
+	return m.Run()
+}
+
+// startEmbedded starts an embedded Postgres on a free port, with its own runtime directory so the
+// test binaries of several packages can run in parallel
+func startEmbedded() (func() error, error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	runtimePath, err := os.MkdirTemp("", "omniflix-testdb-")
+	if err != nil {
+		return nil, err
+	}
+
+	pg := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.V16).
+		Port(port).
+		RuntimePath(runtimePath).
+		StartTimeout(time.Minute).
+		Logger(io.Discard))
+	if err := pg.Start(); err != nil {
+		os.RemoveAll(runtimePath)
+		return nil, err
+	}
+
+	connStr = fmt.Sprintf("host=localhost port=%d user=postgres password=postgres dbname=postgres sslmode=disable", port)
+	return func() error {
+		defer os.RemoveAll(runtimePath)
+		return pg.Stop()
+	}, nil
+}
+
+// freePort returns a TCP port nothing listens on
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return uint32(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+// Open connects to a new empty schema of the test database, dropped when the test ends, so tests
+// never see each other's rows
+func Open(t testing.TB) *sql.DB {
+	t.Helper()
+	if connStr == "" {
+		t.Fatal("testdb: Main was not called from TestMain")
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatal(err)
+	}
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	admin, err := sql.Open("postgres", connStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("error creating schema: %v", err)
+	}
+
+	db, err := sql.Open("postgres", connStr+" search_path="+schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		admin, err := sql.Open("postgres", connStr)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer admin.Close()
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("error dropping schema: %v", err)
+		}
+	})
+	return db
+}
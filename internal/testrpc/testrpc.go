@@ -0,0 +1,139 @@
+// Package testrpc provides a fake CometBFT RPC node serving canned responses to tests
+package testrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Node is a fake RPC node answering requests with the responses registered for their path and query,
+// e.g. "/block?height=5", and with a JSON-RPC error for any other request
+type Node struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string]response
+	requests  map[string]int
+}
+
+type response struct {
+	status int
+	body   string
+}
+
+// NewNode starts a fake RPC node, closed when the test ends
+func NewNode(t testing.TB) *Node {
+	t.Helper()
+	node := &Node{responses: map[string]response{}, requests: map[string]int{}}
+	node.Server = httptest.NewServer(http.HandlerFunc(node.serve))
+	t.Cleanup(node.Close)
+	return node
+}
+
+func (n *Node) serve(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.RequestURI()
+	n.mu.Lock()
+	resp, ok := n.responses[key]
+	n.requests[key]++
+	n.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":-1,"error":{"code":-32603,"message":"Internal error","data":"no response for %s"}}`, key)
+		return
+	}
+	w.WriteHeader(resp.status)
+	fmt.Fprint(w, resp.body)
+}
+
+// Handle answers requests for path, e.g. "/block_results?height=5", with body
+func (n *Node) Handle(path, body string) {
+	n.HandleStatus(path, http.StatusOK, body)
+}
+
+// HandleStatus answers requests for path with status and body
+func (n *Node) HandleStatus(path string, status int, body string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.responses[path] = response{status: status, body: body}
+}
+
+// Requests returns the number of requests received for path
+func (n *Node) Requests(path string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.requests[path]
+}
+
+// Block describes a block served by AddBlock
+type Block struct {
+	Height   int64
+	Hash     string
+	AppHash  string
+	Proposer string
+	Time     time.Time
+	// GasUsed holds the gas used by each tx of the block, which wants twice as much
+	GasUsed []int64
+}
+
+// AddBlock serves the /block and /block_results responses of block
+func (n *Node) AddBlock(block Block) {
+	n.Handle(fmt.Sprintf("/block?height=%d", block.Height), BlockResponse(block))
+	n.Handle(fmt.Sprintf("/block_results?height=%d", block.Height), BlockResultsResponse(block))
+}
+
+// BlockResponse returns the /block response of block; its txs are left out, as the RPC does for empty blocks
+func BlockResponse(block Block) string {
+	return rpcResult(map[string]interface{}{
+		"block_id": map[string]interface{}{"hash": block.Hash},
+		"block": map[string]interface{}{
+			"header": map[string]interface{}{
+				"chain_id":         "omniflixhub-1",
+				"height":           fmt.Sprint(block.Height),
+				"time":             block.Time.UTC().Format(time.RFC3339Nano),
+				"app_hash":         block.AppHash,
+				"proposer_address": block.Proposer,
+			},
+			"data": map[string]interface{}{"txs": nil},
+		},
+	})
+}
+
+// BlockResultsResponse returns the /block_results response of block, with a finalize_block_events
+// event and a transfer event per tx
+func BlockResultsResponse(block Block) string {
+	txsResults := []interface{}{}
+	for _, gasUsed := range block.GasUsed {
+		txsResults = append(txsResults, map[string]interface{}{
+			"code":       0,
+			"gas_used":   fmt.Sprint(gasUsed),
+			"gas_wanted": fmt.Sprint(2 * gasUsed),
+			"events": []interface{}{map[string]interface{}{
+				"type":       "transfer",
+				"attributes": []interface{}{map[string]interface{}{"key": "amount", "value": "1uflix", "index": true}},
+			}},
+		})
+	}
+	return rpcResult(map[string]interface{}{
+		"height":      fmt.Sprint(block.Height),
+		"txs_results": txsResults,
+		"finalize_block_events": []interface{}{map[string]interface{}{
+			"type":       "commission",
+			"attributes": []interface{}{map[string]interface{}{"key": "amount", "value": "1uflix", "index": true}},
+		}},
+	})
+}
+
+// rpcResult wraps result in a JSON-RPC response
+func rpcResult(result interface{}) string {
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": -1, "result": result})
+	if err != nil {
+		panic(err)
+	}
+	return string(body)
+}
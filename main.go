@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/muhammadfarhankt/omniFlix/api"
+	"github.com/muhammadfarhankt/omniFlix/config"
 	"github.com/muhammadfarhankt/omniFlix/db"
 	"github.com/muhammadfarhankt/omniFlix/indexer"
 )
 
+// version is the build version, set at build time with -ldflags "-X main.version=<version>"
+var version = "dev"
+
 func main() {
 	// Initialize database connection (using db.NewDB)
 	dbInstance, err := db.NewDB()
@@ -23,11 +30,16 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Fail fast when the migrations could not bring the schema up to date
+	if err := dbInstance.CheckSchema(); err != nil {
+		log.Fatal(err)
+	}
+
 	// Create an instance of the indexer
-	idx := indexer.NewIndexer(dbInstance.DB)
+	idx := indexer.NewIndexer(dbInstance.DB, dbInstance.Read)
 
 	// Initialize API
-	apiInstance := api.NewAPI(idx)
+	apiInstance := api.NewAPI(idx, version)
 
 	// Continuous indexing (using goroutines and concurrency)
 	//6341001
@@ -38,26 +50,86 @@ func main() {
 	// Find max height from REST API
 	maxBlockHeight := int64(11553690)
 
-	// Fetch the latest block height from the REST API
-	latestHeight, err := idx.GetLatestBlockHeightFromREST()
+	// Fetch the latest block height from the REST API, the RPC or, failing both, the database,
+	// keeping CONFIRMATION_DEPTH blocks below it unindexed
+	latestHeight, err := idx.LatestHeight()
 	if err != nil {
 		log.Printf("Error fetching latest block height: %v", err)
-		latestHeight = maxBlockHeight // Example: Use the initial maxBlockHeight as a fallback
 	}
+	latestHeight = idx.ConfirmedHeight(latestHeight)
 
 	// If latestHeight is greater than maxBlockHeight, update maxBlockHeight
 	if latestHeight > maxBlockHeight {
 		maxBlockHeight = latestHeight
 	}
 
+	// Alert on proposers never seen before in blocks above the current tip when NEW_PROPOSER_WEBHOOK_URL is set
+	if err := idx.StartProposerMonitor(latestHeight); err != nil {
+		log.Printf("Error starting new proposer monitor: %v", err)
+	}
+
+	// INDEX_SINCE indexes a time window, e.g. 720h for the last 30 days, instead of the fixed height range
+	if since := config.Duration("INDEX_SINCE", 0); since > 0 {
+		height, err := idx.HeightAtTime(time.Now().Add(-since))
+		if err != nil {
+			log.Printf("Error finding the height at INDEX_SINCE, keeping min height %d: %v", minBlockHeight, err)
+		} else {
+			log.Printf("Indexing blocks of the last %s, from height %d", since, height)
+			minBlockHeight = height
+		}
+	}
+
+	// Index the latest blocks first so the tip is queryable before the backfill reaches it
+	idx.Warmup(latestHeight, config.Int64("WARMUP_BLOCKS", 100))
+
+	// Cancelled on SIGINT/SIGTERM to shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Strip the details of old blocks when COMPACT_INTERVAL is set
+	go idx.RunCompaction(ctx)
+
+	// Re-index blocks with missing or mismatching data when REPAIR_INTERVAL is set
+	go idx.RunRepair(ctx)
+
+	// Keep the chain height current, which /block/:height checks requested heights against
+	go idx.RunChainHeightRefresh(ctx)
+
+	// Index new blocks as they are announced when TIP_MODE=websocket
+	if idx.Config().TipMode == indexer.TipModeWebsocket {
+		go idx.RunSubscription(ctx)
+	}
+
 	go func() {
-		// infinite loop
-		for {
-			idx.StartIndexing(minBlockHeight, maxBlockHeight)
-			time.Sleep(2 * time.Second) // Wait for 2 seconds before the next indexing cycle
+		// Loop until shutdown
+		for ctx.Err() == nil {
+			// Wait for INDEX_INTERVAL before the next indexing cycle, or INDEX_FAILURE_BACKOFF after an aborted one.
+			// While the WebSocket subscription delivers new blocks, polling slows down to TIP_RESYNC_INTERVAL
+			// and resumes as soon as the subscription drops.
+			wait := idx.Config().Interval
+			if err := idx.StartIndexing(ctx, minBlockHeight, maxBlockHeight); ctx.Err() != nil {
+				return
+			} else if err != nil {
+				log.Printf("%v, retrying in %s", err, idx.Config().FailureBackoff)
+				wait = idx.Config().FailureBackoff
+			} else if idx.Subscribed() {
+				wait = config.Duration("TIP_RESYNC_INTERVAL", time.Minute)
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-idx.SubscriptionLost():
+			case <-time.After(wait):
+			}
 		}
 	}()
 
 	// Start the API
-	apiInstance.Start(":8080")
+	if err := apiInstance.Start(ctx, ":8080"); err != nil {
+		log.Print(err)
+	}
+
+	// Let in-flight block fetches and stores finish, up to SHUTDOWN_TIMEOUT
+	stop()
+	idx.Drain(config.Duration("SHUTDOWN_TIMEOUT", 30*time.Second))
 }